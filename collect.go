@@ -0,0 +1,99 @@
+package errstack
+
+// LVPolicy is a policy for pairs of labels and values with the same
+// label in CollectLV.
+type LVPolicy int
+
+const (
+	// LVKeepAll keeps all pairs with the same label.
+	LVKeepAll LVPolicy = iota
+
+	// LVOutermostWins keeps only the pair of the outermost error
+	// among pairs with the same label.
+	LVOutermostWins
+
+	// LVInnermostWins keeps only the pair of the innermost error
+	// among pairs with the same label.
+	LVInnermostWins
+)
+
+// CollectLV gets the pairs of labels and values of every error
+// in the err's tree and merges them from the outermost error to
+// the innermost error. The tree is traversed in the same order as LV.
+//
+// Unlike LV, pairs attached to an error which is wrapped by another
+// error with other pairs, for example with fmt.Errorf and "%w",
+// are not shadowed by the outer pairs.
+//
+// Pairs which an error inherited from its inner errors with WithLV
+// or Errorf are not collected again. Pairs with the same label and
+// the same value are merged into one. Pairs with the same label and
+// different values are handled according to policy.
+//
+// Values which should be redacted are replaced with RedactedValue.
+func CollectLV(err error, policy LVPolicy) []string {
+	return fieldsLV(redactFields(collectFields(err, policy)))
+}
+
+func collectFields(err error, policy LVPolicy) []Field {
+	var fields []Field
+	walk(err, func(err error) bool {
+		for _, f := range ownFields(err) {
+			if !containsField(fields, f) {
+				fields = append(fields, f)
+			}
+		}
+		return true
+	})
+
+	switch policy {
+	case LVOutermostWins:
+		fields = uniqueLabels(fields)
+	case LVInnermostWins:
+		reverseFields(fields)
+		fields = uniqueLabels(fields)
+		reverseFields(fields)
+	}
+	return fields
+}
+
+// ownFields returns the fields of err itself excluding the fields
+// which err inherited from its inner errors.
+func ownFields(err error) []Field {
+	if e2, ok := err.(*errorWithLV); ok {
+		return e2.fields[e2.inherited:]
+	}
+	return nodeFields(err)
+}
+
+func containsField(fields []Field, f Field) bool {
+	for _, f2 := range fields {
+		if f2.Label == f.Label && f2.Kind == f.Kind && f2.String() == f.String() {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueLabels(fields []Field) []Field {
+	var fields2 []Field
+	for _, f := range fields {
+		found := false
+		for _, f2 := range fields2 {
+			if f2.Label == f.Label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fields2 = append(fields2, f)
+		}
+	}
+	return fields2
+}
+
+func reverseFields(fields []Field) {
+	for i, j := 0, len(fields)-1; i < j; i, j = i+1, j-1 {
+		fields[i], fields[j] = fields[j], fields[i]
+	}
+}
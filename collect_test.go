@@ -0,0 +1,61 @@
+package errstack_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestCollectLV(t *testing.T) {
+	inner := errstack.WithLV(errors.New("my error"), "reqID", "req1", "state", "inner")
+	middle := errstack.Errorf("middle: %w", inner)
+	outer := errstack.WithLV(fmt.Errorf("outer: %w", errstack.WithLV(middle, "userID", "user1")), "state", "outer")
+
+	testCases := []struct {
+		policy errstack.LVPolicy
+		want   []string
+	}{
+		{
+			policy: errstack.LVKeepAll,
+			want:   []string{"state", "outer", "userID", "user1", "reqID", "req1", "state", "inner"},
+		},
+		{
+			policy: errstack.LVOutermostWins,
+			want:   []string{"state", "outer", "userID", "user1", "reqID", "req1"},
+		},
+		{
+			policy: errstack.LVInnermostWins,
+			want:   []string{"userID", "user1", "reqID", "req1", "state", "inner"},
+		},
+	}
+	for _, tc := range testCases {
+		if got := errstack.CollectLV(outer, tc.policy); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("lv unmatch for policy %d, got:%v, want:%v", tc.policy, got, tc.want)
+		}
+	}
+}
+
+func TestCollectLVSameValue(t *testing.T) {
+	inner := errstack.WithLV(errors.New("my error"), "reqID", "req1")
+	outer := errstack.WithLV(fmt.Errorf("outer: %s", inner), "reqID", "req1")
+	err := fmt.Errorf("top: %w", errstack.Join(outer, inner))
+	if got, want := errstack.CollectLV(err, errstack.LVKeepAll), []string{"reqID", "req1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+	}
+}
+
+func TestCollectLVJoin(t *testing.T) {
+	err := errstack.WithLV(errstack.Join(
+		errstack.WithLV(errors.New("error1"), "item", "1"),
+		errstack.WithLV(errors.New("error2"), "item", "2"),
+	), "batch", "b1")
+	if got, want := errstack.LV(err), []string{"batch", "b1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+	}
+	if got, want := errstack.CollectLV(err, errstack.LVKeepAll), []string{"batch", "b1", "item", "1", "item", "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+	}
+}
@@ -0,0 +1,82 @@
+package errstack
+
+import (
+	"context"
+	"errors"
+)
+
+type lvContextKey struct{}
+
+// ContextWithLV returns a copy of ctx which has pairs of labels and
+// values. If ctx already has pairs, the pairs of the returned context
+// are the concatenation of those and the lv argument.
+//
+// The pairs can be attached to errors created with NewCtx and ErrorfCtx.
+func ContextWithLV(ctx context.Context, lv ...string) context.Context {
+	if len(lv)%2 == 1 {
+		panic("lv must be label and value pairs")
+	}
+
+	fields := contextFields(ctx)
+	fields2 := make([]Field, len(fields), len(fields)+len(lv)/2)
+	copy(fields2, fields)
+	for i := 0; i < len(lv); i += 2 {
+		fields2 = append(fields2, stringField(lv[i], lv[i+1]))
+	}
+	return context.WithValue(ctx, lvContextKey{}, fields2)
+}
+
+// LVFromContext returns the pairs of labels and values of ctx
+// added with ContextWithLV.
+func LVFromContext(ctx context.Context) []string {
+	return fieldsLV(redactFields(contextFields(ctx)))
+}
+
+func contextFields(ctx context.Context) []Field {
+	fields, _ := ctx.Value(lvContextKey{}).([]Field)
+	return fields
+}
+
+// NewCtx is the same as New except that the pairs of labels and
+// values of ctx are attached to the returned error.
+func NewCtx(ctx context.Context, text string) error {
+	err := &errorWithStack{
+		err:   errors.New(text),
+		stack: callers(3),
+	}
+	return withContextLV(ctx, err)
+}
+
+// ErrorfCtx is the same as Errorf except that the pairs of labels and
+// values of ctx are appended to the pairs of the returned error.
+//
+// Pairs of ctx which the arguments already have, for example when an
+// argument was created with ErrorfCtx with the same ctx, are not added
+// again.
+func ErrorfCtx(ctx context.Context, format string, a ...interface{}) error {
+	return withContextLV(ctx, errorf(options{skip: 4}, format, a...))
+}
+
+func withContextLV(ctx context.Context, err error) error {
+	ctxFields := contextFields(ctx)
+	if len(ctxFields) == 0 {
+		return err
+	}
+
+	fields := inheritedFields(err)
+	e2 := &errorWithLV{
+		err:       err,
+		fields:    make([]Field, len(fields), len(fields)+len(ctxFields)),
+		inherited: len(fields),
+	}
+	copy(e2.fields, fields)
+	for _, f := range ctxFields {
+		if !containsField(fields, f) {
+			e2.fields = append(e2.fields, f)
+		}
+	}
+	if len(e2.fields) == len(fields) {
+		return err
+	}
+	return e2
+}
@@ -0,0 +1,72 @@
+package errstack_test
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestContextWithLV(t *testing.T) {
+	ctx := errstack.ContextWithLV(context.Background(), "reqID", "req1")
+	ctx2 := errstack.ContextWithLV(ctx, "tenantID", "t1")
+	if got, want := errstack.LVFromContext(ctx), []string{"reqID", "req1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+	}
+	if got, want := errstack.LVFromContext(ctx2), []string{"reqID", "req1", "tenantID", "t1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+	}
+	if got := errstack.LVFromContext(context.Background()); got != nil {
+		t.Errorf("lv unmatch, got:%v, want:nil", got)
+	}
+}
+
+func TestNewCtx(t *testing.T) {
+	ctx := errstack.ContextWithLV(context.Background(), "reqID", "req1")
+	err := testNewCtxLevel1(ctx)
+	if got, want := errstack.LV(err), []string{"reqID", "req1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+	}
+	testStackFrameNames(t, errstack.Stack(err), []string{
+		"github.com/hnakamur/errstack_test.testNewCtxLevel1",
+		"github.com/hnakamur/errstack_test.TestNewCtx",
+	})
+
+	if got, want := errstack.LV(testNewCtxLevel1(context.Background())), []string(nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+	}
+}
+
+func TestErrorfCtx(t *testing.T) {
+	ctx := errstack.ContextWithLV(context.Background(), "reqID", "req1")
+	t.Run("generateStack", func(t *testing.T) {
+		err := testErrorfCtxLevel2(ctx)
+		if got, want := errstack.LV(err), []string{"reqID", "req1"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+		}
+		testStackFrameNames(t, errstack.Stack(err), []string{
+			"github.com/hnakamur/errstack_test.testErrorfCtxLevel2",
+			"github.com/hnakamur/errstack_test.TestErrorfCtx.func1",
+		})
+	})
+	t.Run("inherit", func(t *testing.T) {
+		inner := errstack.WithLV(testNewCtxLevel1(ctx), "userID", "user1")
+		err := errstack.ErrorfCtx(ctx, "outer: %w", inner)
+		if got, want := errstack.LV(err), []string{"reqID", "req1", "userID", "user1"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+		}
+		testStackFrameNames(t, errstack.Stack(err), []string{
+			"github.com/hnakamur/errstack_test.testNewCtxLevel1",
+		})
+	})
+}
+
+func testNewCtxLevel1(ctx context.Context) error {
+	return errstack.NewCtx(ctx, "my error")
+}
+
+func testErrorfCtxLevel2(ctx context.Context) error {
+	return errstack.ErrorfCtx(ctx, "level2: %w", os.ErrNotExist)
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"runtime"
 	"strconv"
+	"sync"
 	"sync/atomic"
 )
 
@@ -19,14 +20,49 @@ var MaxFrames = uint32(128)
 
 type errorWithStack struct {
 	err   error
-	stack []Frame
+	stack *stack
+
+	// wrap is the call stack frame where the error was wrapped,
+	// which is recorded if SetWrapPointRecording is enabled.
+	wrap *stack
+
+	// stacks is all the stacks inherited by Errorf under InheritAll
+	// if there are more than one.
+	stacks []*stack
+}
+
+// stack holds program counters returned by runtime.Callers.
+// They are resolved to call stack frames the first time
+// frames is called, since most errors are never asked for
+// their stack.
+type stack struct {
+	pcs    []uintptr
+	once   sync.Once
+	frames []Frame
+
+	// goroutine is recorded if SetGoroutineRecording is enabled.
+	goroutine *goroutineInfo
 }
 
 // Frame is a call stack frame.
 type Frame struct {
 	Name string
 	Line int
+
+	// Path is the path of the file, which is rewritten with the
+	// configuration set with SetPathOptions.
 	Path string
+
+	// PC is the program counter of the frame.
+	PC uintptr
+
+	// Entry is the entry address of the function. For an inlined
+	// frame, it is the entry address of the function in which the
+	// function is inlined.
+	Entry uintptr
+
+	// Inlined reports whether the function is inlined into its caller.
+	Inlined bool
 }
 
 // New creates an error with errors.New and
@@ -41,7 +77,7 @@ type Frame struct {
 // Call stack frames can be obtained by calling the Stack
 // function later at the upper call frame.
 func New(text string) error {
-	s := stacks(3)
+	s := callers(3)
 	return &errorWithStack{
 		err:   errors.New(text),
 		stack: s,
@@ -65,69 +101,205 @@ func New(text string) error {
 // then stack call frames are generated and set to the
 // wrapped error.
 //
+// Which argument the pairs and the stack call frames are taken
+// from when several arguments have them can be changed with
+// SetInheritPolicy.
+//
 // The original error returned by fmt.Errorf can be
 // obtained by calling Unwrap method of the wrapped error.
 //
 // Call stack frames can be obtained by calling the Stack
 // function later at the upper call frame.
 func Errorf(format string, a ...interface{}) error {
-	err := fmt.Errorf(format, a...)
+	return errorf(options{skip: 4}, format, a...)
+}
 
-	var lv []string
-	for i := len(a) - 1; i >= 0; i-- {
-		if e2, ok := a[i].(error); ok {
-			if lv2 := LV(e2); lv2 != nil {
-				lv = lv2
-				break
-			}
-		}
+// NewSkip is the same as New except that skip frames are skipped
+// in addition to the frame of the caller of NewSkip.
+// NewSkip(0, text) is the same as New(text), and NewSkip(1, text)
+// records the caller of the function which calls NewSkip as the top
+// frame, which is useful for helper functions creating errors.
+func NewSkip(skip int, text string) error {
+	return &errorWithStack{
+		err:   errors.New(text),
+		stack: callersN(3+nonNegative(skip), 0),
 	}
+}
 
-	var s []Frame
-	for i := len(a) - 1; i >= 0; i-- {
-		if e2, ok := a[i].(error); ok {
-			if s2 := Stack(e2); s2 != nil {
-				s = s2
-				break
-			}
-		}
+// ErrorfSkip is the same as Errorf except that skip frames are skipped
+// in the same way as NewSkip if call stack frames are generated.
+func ErrorfSkip(skip int, format string, a ...interface{}) error {
+	return errorf(options{skip: 4 + nonNegative(skip)}, format, a...)
+}
+
+// options is the configuration of creating an error.
+type options struct {
+	// skip is passed to runtime.Callers in errorf.
+	skip int
+
+	// depth is the maximum frame count. MaxFrames is used if it is zero.
+	depth int
+
+	// force is true if call stack frames are generated even if an
+	// argument has them.
+	force bool
+
+	// inherit is used instead of the policy set with SetInheritPolicy
+	// if hasInherit is true.
+	inherit    InheritPolicy
+	hasInherit bool
+}
+
+// errorf is the implementation of Errorf.
+func errorf(opts options, format string, a ...interface{}) error {
+	err := fmt.Errorf(format, a...)
+
+	fields, s, stacks := inherit(opts.inheritPolicy(), format, a)
+	if opts.force {
+		s, stacks = nil, nil
 	}
 	if s == nil {
-		s = stacks(3)
+		s = callersN(opts.skip, opts.depth)
 	}
 
 	err = &errorWithStack{
+		err:    err,
+		stack:  s,
+		wrap:   wrapPointStack(opts.skip),
+		stacks: stacks,
+	}
+	if fields == nil {
+		return err
+	}
+	return &errorWithLV{err: err, fields: fields, inherited: len(fields)}
+}
+
+// Wrap returns an error which wraps err with call stack frames.
+// The message of the returned error is the same as that of err.
+//
+// If err's tree has stack call frames, those are set to the wrapped
+// error in the same way as Errorf. Otherwise call stack frames are
+// generated and set to the wrapped error.
+//
+// Wrap returns nil if err is nil.
+func Wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	s := findStack(err)
+	if s == nil {
+		s = callers(3)
+	}
+	return &errorWithStack{
 		err:   err,
 		stack: s,
+		wrap:  wrapPointStack(3),
 	}
-	if lv == nil {
-		return err
+}
+
+// Wrapf returns an error which wraps err with call stack frames.
+// The message of the returned error is the result of fmt.Sprintf
+// with format and a, followed by a colon, a space and the message
+// of err.
+//
+// The call stack frames are set in the same way as Wrap.
+//
+// Wrapf returns nil if err is nil.
+func Wrapf(err error, format string, a ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	s := findStack(err)
+	if s == nil {
+		s = callers(3)
+	}
+	return &errorWithStack{
+		err:   fmt.Errorf("%s: %w", fmt.Sprintf(format, a...), err),
+		stack: s,
+		wrap:  wrapPointStack(3),
 	}
-	return &errorWithLV{err: err, lv: lv}
 }
 
-// Stack finds the first error in err's chain that has stack call frames,
+// Stack finds the first error in err's tree that has stack call frames,
 // and returns those if found.
 //
+// The tree is traversed in depth-first pre-order in the same way as
+// errors.Is, following both Unwrap() error and Unwrap() []error methods.
+//
 // Note you need to build an error chain only with fmt.Errorf with "%w",
 // errstack.Errorf, and errstack.New in order to get stack frames.
 // Otherwise Stack returns nil.
 func Stack(err error) []Frame {
-	if err == nil {
-		return nil
-	}
-	for {
+	var s []Frame
+	walk(err, func(err error) bool {
 		if e2, ok := err.(interface{ Stack() []Frame }); ok {
-			return e2.Stack()
+			s = e2.Stack()
+		}
+		return s == nil
+	})
+	return s
+}
+
+// Stacks returns the stack call frames of every error in err's tree
+// that has them, in the same order as Stack traverses the tree.
+//
+// If an error has the Stacks() [][]Frame method, all of the
+// results are used instead of the result of its Stack method.
+//
+// Stacks shared by errors in the tree, for example a stack which
+// Errorf inherited from its argument, are returned only once.
+func Stacks(err error) [][]Frame {
+	var ss [][]Frame
+	add := func(s []Frame) {
+		if len(s) > 0 && !containsFrames(ss, s) {
+			ss = append(ss, s)
+		}
+	}
+	walk(err, func(err error) bool {
+		switch e2 := err.(type) {
+		case interface{ Stacks() [][]Frame }:
+			for _, s := range e2.Stacks() {
+				add(s)
+			}
+		case interface{ Stack() []Frame }:
+			add(e2.Stack())
 		}
+		return true
+	})
+	return ss
+}
 
-		if e2, ok := err.(interface{ Unwrap() error }); ok {
+func containsFrames(ss [][]Frame, s []Frame) bool {
+	for _, s2 := range ss {
+		if &s2[0] == &s[0] && len(s2) == len(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// walk calls fn for err and for every error in err's tree in depth-first
+// pre-order until fn returns false. walk returns false if fn returned false.
+func walk(err error, fn func(error) bool) bool {
+	for err != nil {
+		if !fn(err) {
+			return false
+		}
+		switch e2 := err.(type) {
+		case interface{ Unwrap() error }:
 			err = e2.Unwrap()
-		} else {
-			break
+		case interface{ Unwrap() []error }:
+			for _, e3 := range e2.Unwrap() {
+				if !walk(e3, fn) {
+					return false
+				}
+			}
+			return true
+		default:
+			return true
 		}
 	}
-	return nil
+	return true
 }
 
 func (e *errorWithStack) Error() string {
@@ -139,7 +311,46 @@ func (e *errorWithStack) Unwrap() error {
 }
 
 func (e *errorWithStack) Stack() []Frame {
-	return e.stack
+	return e.stack.Frames()
+}
+
+// Stacks returns the stack call frames of every argument which Errorf
+// inherited under InheritAll, in the order of the arguments.
+// Otherwise it returns the result of Stack.
+func (e *errorWithStack) Stacks() [][]Frame {
+	if len(e.stacks) == 0 {
+		return [][]Frame{e.Stack()}
+	}
+	ss := make([][]Frame, len(e.stacks))
+	for i, s := range e.stacks {
+		ss[i] = s.Frames()
+	}
+	return ss
+}
+
+// findStack returns the stack of the first error in err's tree
+// that has stack call frames. The stack of an errorWithStack is
+// shared as is, so that it is not resolved before it is needed.
+//
+// Unlike inheritedFields, it descends into multi-errors without
+// stacks such as the ones returned by errors.Join, since the stack of
+// a joined error is the only place where the error happened. An error
+// returned by Join has its own stack, so an error wrapping it inherits
+// that stack rather than the stack of one of the joined errors.
+func findStack(err error) *stack {
+	var s *stack
+	walk(err, func(err error) bool {
+		switch e2 := err.(type) {
+		case *errorWithStack:
+			s = e2.stack
+		case interface{ Stack() []Frame }:
+			if frames := e2.Stack(); frames != nil {
+				s = &stack{frames: frames}
+			}
+		}
+		return s == nil
+	})
+	return s
 }
 
 // String retruns a string representation for the stack.
@@ -153,21 +364,72 @@ func (f *Frame) String() string {
 	return string(b)
 }
 
-func stacks(skip int) []Frame {
-	var ss []Frame
-	pcs := make([]uintptr, atomic.LoadUint32(&MaxFrames))
-	runtime.Callers(skip, pcs)
+func callers(skip int) *stack {
+	return callersN(skip+1, 0)
+}
+
+// callersN is the same as callers except that at most depth frames
+// are recorded. MaxFrames is used if depth is zero.
+func callersN(skip, depth int) *stack {
+	if depth <= 0 {
+		depth = int(atomic.LoadUint32(&MaxFrames))
+	}
+	// The buffer on the stack avoids allocating a scratch buffer of
+	// depth elements with the default MaxFrames, and only the captured
+	// program counters are copied to the heap.
+	var buf [128]uintptr
+	var pcs []uintptr
+	if depth <= len(buf) {
+		pcs = buf[:depth]
+	} else {
+		pcs = make([]uintptr, depth)
+	}
+	n := runtime.Callers(skip, pcs)
+	pcs2 := make([]uintptr, n)
+	copy(pcs2, pcs)
+	return &stack{pcs: pcs2, goroutine: currentGoroutine()}
+}
+
+func nonNegative(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// Frames resolves the program counters to call stack frames, and
+// drops frames with the filter set with SetFrameFilter.
+// It is safe to call Frames from multiple goroutines.
+func (s *stack) Frames() []Frame {
+	s.once.Do(func() {
+		if s.frames == nil {
+			s.frames = filterFrames(resolveFrames(s.pcs))
+		}
+	})
+	return s.frames
+}
+
+func resolveFrames(pcs []uintptr) []Frame {
+	if len(pcs) == 0 {
+		return nil
+	}
+	opts := getPathOptions()
+	ss := make([]Frame, 0, len(pcs))
 	frames := runtime.CallersFrames(pcs)
 	for {
 		frame, more := frames.Next()
+		path, _ := opts.trim(frame.File)
+		ss = append(ss, Frame{
+			Name:    frame.Function,
+			Line:    frame.Line,
+			Path:    path,
+			PC:      frame.PC,
+			Entry:   frame.Entry,
+			Inlined: frame.Func == nil,
+		})
 		if !more {
 			break
 		}
-		ss = append(ss, Frame{
-			Name: frame.Function,
-			Line: frame.Line,
-			Path: frame.File,
-		})
 	}
 	return ss
 }
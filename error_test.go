@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/hnakamur/errstack"
@@ -110,6 +112,41 @@ func testStackWrapOnlyAtMiddleNoGoodLevel2() error {
 }
 func testStackWrapOnlyAtMiddleNoGoodLevel1() error { return os.ErrExist }
 
+func TestStackConcurrent(t *testing.T) {
+	err := testStackWrapOnlyAtBottomLevel2()
+	want := errstack.Stack(testStackWrapOnlyAtBottomLevel2())
+
+	const n = 8
+	got := make([][]errstack.Frame, n)
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			got[i] = errstack.Stack(err)
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < n; i++ {
+		testStackFrameNames(t, got[i], []string{
+			"github.com/hnakamur/errstack_test.testStackWrapOnlyAtBottomLevel1",
+			"github.com/hnakamur/errstack_test.testStackWrapOnlyAtBottomLevel2",
+			"github.com/hnakamur/errstack_test.TestStackConcurrent",
+		})
+		if len(got[i]) != len(want) {
+			t.Errorf("unmatch stack depth, got:%d, want:%d", len(got[i]), len(want))
+		}
+	}
+}
+
+func TestStackInherited(t *testing.T) {
+	inner := testStackWrapOnlyAtBottomLevel1()
+	outer := errstack.Errorf("outer: %w", inner)
+	if got, want := errstack.Stack(outer), errstack.Stack(inner); !reflect.DeepEqual(got, want) {
+		t.Errorf("unmatch stack, got:%v, want:%v", got, want)
+	}
+}
+
 func TestStackWithLV(t *testing.T) {
 	t.Run("wrapAtTop", func(t *testing.T) {
 		err := testStackWithLVWrapAtTopLevel2()
@@ -454,3 +491,65 @@ func testStackFrameNames(t *testing.T, frames []errstack.Frame, names []string)
 		}
 	}
 }
+
+func TestNewSkip(t *testing.T) {
+	testCases := []struct {
+		skip        int
+		newNames    []string
+		errorfNames []string
+	}{
+		{
+			skip: 0,
+			newNames: []string{
+				"github.com/hnakamur/errstack_test.testNewSkipHelper",
+				"github.com/hnakamur/errstack_test.TestNewSkip.func1",
+			},
+			errorfNames: []string{
+				"github.com/hnakamur/errstack_test.testErrorfSkipHelper",
+				"github.com/hnakamur/errstack_test.TestNewSkip.func1",
+			},
+		},
+		{
+			skip: 1,
+			newNames: []string{
+				"github.com/hnakamur/errstack_test.TestNewSkip.func1",
+			},
+			errorfNames: []string{
+				"github.com/hnakamur/errstack_test.TestNewSkip.func1",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(fmt.Sprintf("skip%d", tc.skip), func(t *testing.T) {
+			testStackFrameNames(t, errstack.Stack(testNewSkipHelper(tc.skip)), tc.newNames)
+			testStackFrameNames(t, errstack.Stack(testErrorfSkipHelper(tc.skip)), tc.errorfNames)
+		})
+	}
+	t.Run("inherit", func(t *testing.T) {
+		inner := errstack.New("inner")
+		err := testErrorfSkipHelper2(1, inner)
+		if got, want := errstack.Stack(err), errstack.Stack(inner); &got[0] != &want[0] {
+			t.Errorf("stack must be inherited")
+		}
+	})
+}
+
+func testNewSkipHelper(skip int) error {
+	return errstack.NewSkip(skip, "my error")
+}
+
+func testErrorfSkipHelper(skip int) error {
+	return errstack.ErrorfSkip(skip, "my error: %w", os.ErrNotExist)
+}
+
+func testErrorfSkipHelper2(skip int, err error) error {
+	return errstack.ErrorfSkip(skip, "my error: %w", err)
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = errstack.New("my error")
+	}
+}
@@ -0,0 +1,421 @@
+package errstack
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Kind is the kind of the value of a Field.
+type Kind int
+
+// The kinds of the values of fields.
+const (
+	KindString Kind = iota
+	KindInt64
+	KindUint64
+	KindFloat64
+	KindBool
+	KindTime
+	KindDuration
+	KindBytes
+	KindGroup
+)
+
+var kindNames = []string{
+	KindString:   "string",
+	KindInt64:    "int64",
+	KindUint64:   "uint64",
+	KindFloat64:  "float64",
+	KindBool:     "bool",
+	KindTime:     "time",
+	KindDuration: "duration",
+	KindBytes:    "bytes",
+	KindGroup:    "group",
+}
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	if k >= 0 && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Field is a pair of a label and a typed value attached to an error.
+type Field struct {
+	Label string
+	Kind  Kind
+
+	num uint64      // int64, uint64, float64 bits, bool, or time.Duration
+	str string      // string, or time layout
+	any interface{} // time.Time, []byte, []Field, or the bit size of float
+
+	secret bool
+}
+
+// NewField returns a field with the label and the value.
+//
+// The kind of the field is chosen by the type of value.
+// Signed integers are stored as KindInt64, unsigned integers as
+// KindUint64, float32 and float64 as KindFloat64, time.Time as KindTime
+// formatted with time.RFC3339, time.Duration as KindDuration, []byte as
+// KindBytes and []Field as KindGroup.
+// Other values are stored as KindString formatted with fmt.Sprint.
+func NewField(label string, value interface{}) Field {
+	switch v := value.(type) {
+	case string:
+		return stringField(label, v)
+	case int:
+		return int64Field(label, int64(v))
+	case int8:
+		return int64Field(label, int64(v))
+	case int16:
+		return int64Field(label, int64(v))
+	case int32:
+		return int64Field(label, int64(v))
+	case int64:
+		return int64Field(label, v)
+	case uint:
+		return uint64Field(label, uint64(v))
+	case uint8:
+		return uint64Field(label, uint64(v))
+	case uint16:
+		return uint64Field(label, uint64(v))
+	case uint32:
+		return uint64Field(label, uint64(v))
+	case uint64:
+		return uint64Field(label, v)
+	case uintptr:
+		return uint64Field(label, uint64(v))
+	case float32:
+		return floatField(label, float64(v), 32)
+	case float64:
+		return floatField(label, v, 64)
+	case bool:
+		return boolField(label, v)
+	case time.Time:
+		return timeField(label, v, time.RFC3339)
+	case time.Duration:
+		return durationField(label, v)
+	case []byte:
+		return bytesField(label, v)
+	case []Field:
+		return groupField(label, v)
+	default:
+		return stringField(label, fmt.Sprint(v))
+	}
+}
+
+func stringField(label, value string) Field {
+	return Field{Label: label, Kind: KindString, str: value}
+}
+
+func int64Field(label string, value int64) Field {
+	return Field{Label: label, Kind: KindInt64, num: uint64(value)}
+}
+
+func uint64Field(label string, value uint64) Field {
+	return Field{Label: label, Kind: KindUint64, num: value}
+}
+
+func floatField(label string, value float64, bitSize int) Field {
+	return Field{Label: label, Kind: KindFloat64, num: math.Float64bits(value), any: bitSize}
+}
+
+func boolField(label string, value bool) Field {
+	f := Field{Label: label, Kind: KindBool}
+	if value {
+		f.num = 1
+	}
+	return f
+}
+
+func timeField(label string, value time.Time, layout string) Field {
+	return Field{Label: label, Kind: KindTime, str: layout, any: value}
+}
+
+func durationField(label string, value time.Duration) Field {
+	return Field{Label: label, Kind: KindDuration, num: uint64(value)}
+}
+
+func bytesField(label string, value []byte) Field {
+	v := make([]byte, len(value))
+	copy(v, value)
+	return Field{Label: label, Kind: KindBytes, any: v}
+}
+
+func groupField(label string, value []Field) Field {
+	v := make([]Field, len(value))
+	copy(v, value)
+	return Field{Label: label, Kind: KindGroup, any: v}
+}
+
+// stringFields converts pairs of labels and values to string fields.
+func stringFields(lv []string) []Field {
+	if len(lv) == 0 {
+		return nil
+	}
+	fields := make([]Field, 0, len(lv)/2)
+	for i := 0; i+1 < len(lv); i += 2 {
+		fields = append(fields, stringField(lv[i], lv[i+1]))
+	}
+	return fields
+}
+
+// fieldsLV converts fields to pairs of labels and values
+// with values formatted with Field.String.
+func fieldsLV(fields []Field) []string {
+	if len(fields) == 0 {
+		return nil
+	}
+	lv := make([]string, 0, 2*len(fields))
+	for _, f := range fields {
+		lv = append(lv, f.Label, f.String())
+	}
+	return lv
+}
+
+// Value returns the raw value of the field.
+//
+// The type of the returned value is string for KindString, int64 for
+// KindInt64, uint64 for KindUint64, float64 for KindFloat64, bool for
+// KindBool, time.Time for KindTime, time.Duration for KindDuration,
+// []byte for KindBytes, and []Field for KindGroup.
+// The returned []byte and []Field are copies, so modifying them does not
+// affect the field.
+//
+// For a field of KindTime, KindBytes or KindGroup which was not created
+// with NewField or the methods of ErrorWithLV, for example a Field
+// literal, the value is a string, which is empty for a Field literal.
+func (f Field) Value() interface{} {
+	switch f.Kind {
+	case KindInt64:
+		return int64(f.num)
+	case KindUint64:
+		return f.num
+	case KindFloat64:
+		return math.Float64frombits(f.num)
+	case KindBool:
+		return f.num != 0
+	case KindDuration:
+		return time.Duration(f.num)
+	case KindTime:
+		if v, ok := f.any.(time.Time); ok {
+			return v
+		}
+	case KindBytes:
+		if v, ok := f.any.([]byte); ok {
+			return append([]byte{}, v...)
+		}
+	case KindGroup:
+		if v, ok := f.any.([]Field); ok {
+			return append([]Field{}, v...)
+		}
+	}
+	return f.str
+}
+
+// String returns the value formatted as a string, which is used
+// as the value in the result of the LV function.
+//
+// Bytes are formatted in hexadecimal, and a group is formatted
+// like "{label1=value1 label2=value2}".
+func (f Field) String() string {
+	switch f.Kind {
+	case KindInt64:
+		return strconv.FormatInt(int64(f.num), 10)
+	case KindUint64:
+		return strconv.FormatUint(f.num, 10)
+	case KindFloat64:
+		bitSize, ok := f.any.(int)
+		if !ok {
+			bitSize = 64
+		}
+		return strconv.FormatFloat(math.Float64frombits(f.num), 'g', -1, bitSize)
+	case KindBool:
+		return strconv.FormatBool(f.num != 0)
+	case KindTime:
+		if v, ok := f.any.(time.Time); ok {
+			return v.Format(f.str)
+		}
+	case KindDuration:
+		return time.Duration(f.num).String()
+	case KindBytes:
+		if v, ok := f.any.([]byte); ok {
+			return hex.EncodeToString(v)
+		}
+	case KindGroup:
+		fields, ok := f.any.([]Field)
+		if !ok {
+			break
+		}
+		var b bytes.Buffer
+		b.WriteByte('{')
+		for i, f2 := range fields {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(f2.Label)
+			b.WriteByte('=')
+			b.WriteString(f2.String())
+		}
+		b.WriteByte('}')
+		return b.String()
+	}
+	return f.str
+}
+
+type jsonField struct {
+	Label string          `json:"label"`
+	Kind  string          `json:"kind,omitempty"`
+	Value json.RawMessage `json:"value"`
+}
+
+// MarshalJSON implements json.Marshaler.
+//
+// A field is encoded as an object with "label", "kind" and "value"
+// members. The kind is the name returned by Kind.String.
+// A field which should be redacted is encoded as a string field
+// with RedactedValue.
+// The value is a JSON number for KindInt64, KindUint64 and KindFloat64,
+// a JSON boolean for KindBool, an array of fields for KindGroup, and
+// a JSON string formatted with Field.String for other kinds.
+// NaN and infinities of KindFloat64 are encoded as JSON strings
+// since JSON numbers cannot represent them.
+func (f Field) MarshalJSON() ([]byte, error) {
+	f, _ = f.redact()
+	var v []byte
+	var err error
+	switch f.Kind {
+	case KindInt64, KindUint64, KindBool:
+		v = []byte(f.String())
+	case KindFloat64:
+		if fv := math.Float64frombits(f.num); math.IsNaN(fv) || math.IsInf(fv, 0) {
+			v, err = json.Marshal(f.String())
+		} else {
+			v = []byte(f.String())
+		}
+	case KindGroup:
+		fields, _ := f.any.([]Field)
+		if fields == nil {
+			fields = []Field{}
+		}
+		v, err = json.Marshal(fields)
+	default:
+		v, err = json.Marshal(f.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonField{Label: f.Label, Kind: f.Kind.String(), Value: v})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+//
+// A value of KindTime is parsed with time.RFC3339Nano. If it fails,
+// the field is decoded as KindString. A field without the "kind" member
+// is decoded as KindString.
+func (f *Field) UnmarshalJSON(data []byte) error {
+	var f2 jsonField
+	if err := json.Unmarshal(data, &f2); err != nil {
+		return err
+	}
+	kind := KindString
+	if f2.Kind != "" {
+		k, err := parseKind(f2.Kind)
+		if err != nil {
+			return err
+		}
+		kind = k
+	}
+
+	switch kind {
+	case KindInt64:
+		v, err := strconv.ParseInt(string(f2.Value), 10, 64)
+		if err != nil {
+			return err
+		}
+		*f = int64Field(f2.Label, v)
+	case KindUint64:
+		v, err := strconv.ParseUint(string(f2.Value), 10, 64)
+		if err != nil {
+			return err
+		}
+		*f = uint64Field(f2.Label, v)
+	case KindFloat64:
+		text := string(f2.Value)
+		var s string
+		if err := json.Unmarshal(f2.Value, &s); err == nil {
+			text = s
+		}
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return err
+		}
+		*f = floatField(f2.Label, v, 64)
+	case KindBool:
+		var v bool
+		if err := json.Unmarshal(f2.Value, &v); err != nil {
+			return err
+		}
+		*f = boolField(f2.Label, v)
+	case KindGroup:
+		var v []Field
+		if err := json.Unmarshal(f2.Value, &v); err != nil {
+			return err
+		}
+		*f = groupField(f2.Label, v)
+	default:
+		var s string
+		if err := json.Unmarshal(f2.Value, &s); err != nil {
+			return err
+		}
+		switch kind {
+		case KindTime:
+			if v, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				*f = timeField(f2.Label, v, time.RFC3339Nano)
+				return nil
+			}
+			*f = stringField(f2.Label, s)
+		case KindDuration:
+			v, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			*f = durationField(f2.Label, v)
+		case KindBytes:
+			v, err := hex.DecodeString(s)
+			if err != nil {
+				return err
+			}
+			*f = bytesField(f2.Label, v)
+		default:
+			*f = stringField(f2.Label, s)
+		}
+	}
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder using the JSON encoding.
+func (f Field) GobEncode() ([]byte, error) {
+	return f.MarshalJSON()
+}
+
+// GobDecode implements gob.GobDecoder using the JSON encoding.
+func (f *Field) GobDecode(data []byte) error {
+	return f.UnmarshalJSON(data)
+}
+
+func parseKind(name string) (Kind, error) {
+	for k, name2 := range kindNames {
+		if name2 == name {
+			return Kind(k), nil
+		}
+	}
+	return KindString, fmt.Errorf("errstack: unknown field kind %q", name)
+}
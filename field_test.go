@@ -0,0 +1,216 @@
+package errstack_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestFields(t *testing.T) {
+	tm := time.Date(2019, 10, 22, 5, 31, 53, 0, time.UTC)
+	err := errstack.WithLV(errors.New("my error"), "reqID", "req1").
+		Int("int", -12).
+		Uint8("uint8", 12).
+		Float32("float32", 1.2).
+		Bool("bool", true).
+		Time("time", tm, "").
+		Duration("duration", 1500*time.Millisecond).
+		HexBytes("bytes", []byte{'\xba', '\xbe'}).
+		Group("group", errstack.NewField("a", 1), errstack.NewField("b", "x"))
+	err2 := fmt.Errorf("outer: %w", err)
+
+	testCases := []struct {
+		kind  errstack.Kind
+		value interface{}
+		str   string
+	}{
+		{kind: errstack.KindString, value: "req1", str: "req1"},
+		{kind: errstack.KindInt64, value: int64(-12), str: "-12"},
+		{kind: errstack.KindUint64, value: uint64(12), str: "12"},
+		{kind: errstack.KindFloat64, value: float64(float32(1.2)), str: "1.2"},
+		{kind: errstack.KindBool, value: true, str: "true"},
+		{kind: errstack.KindTime, value: tm, str: "2019-10-22T05:31:53Z"},
+		{kind: errstack.KindDuration, value: 1500 * time.Millisecond, str: "1.5s"},
+		{kind: errstack.KindBytes, value: []byte{'\xba', '\xbe'}, str: "babe"},
+		{kind: errstack.KindGroup, value: []errstack.Field{errstack.NewField("a", 1), errstack.NewField("b", "x")}, str: "{a=1 b=x}"},
+	}
+	fields := errstack.Fields(err2)
+	if got, want := len(fields), len(testCases); got != want {
+		t.Fatalf("unmatch field count, got:%d, want:%d", got, want)
+	}
+	for i, tc := range testCases {
+		f := fields[i]
+		if got, want := f.Kind, tc.kind; got != want {
+			t.Errorf("unmatch kind of %s, got:%v, want:%v", f.Label, got, want)
+		}
+		if got, want := f.Value(), tc.value; !reflect.DeepEqual(got, want) {
+			t.Errorf("unmatch value of %s, got:%v, want:%v", f.Label, got, want)
+		}
+		if got, want := f.String(), tc.str; got != want {
+			t.Errorf("unmatch string of %s, got:%s, want:%s", f.Label, got, want)
+		}
+	}
+
+	lv := errstack.LV(err2)
+	if got, want := lv[:4], []string{"reqID", "req1", "int", "-12"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+	}
+}
+
+func TestFieldsFromLV(t *testing.T) {
+	err := testFieldsLVError{}
+	want := []errstack.Field{errstack.NewField("reqID", "req1")}
+	if got := errstack.Fields(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("unmatch fields, got:%v, want:%v", got, want)
+	}
+}
+
+type testFieldsLVError struct{}
+
+func (testFieldsLVError) Error() string { return "my error" }
+func (testFieldsLVError) LV() []string  { return []string{"reqID", "req1"} }
+
+func TestNewField(t *testing.T) {
+	testCases := []struct {
+		value interface{}
+		kind  errstack.Kind
+	}{
+		{value: "s", kind: errstack.KindString},
+		{value: int8(1), kind: errstack.KindInt64},
+		{value: uint(1), kind: errstack.KindUint64},
+		{value: 1.5, kind: errstack.KindFloat64},
+		{value: false, kind: errstack.KindBool},
+		{value: time.Now(), kind: errstack.KindTime},
+		{value: time.Second, kind: errstack.KindDuration},
+		{value: []byte("a"), kind: errstack.KindBytes},
+		{value: []errstack.Field{}, kind: errstack.KindGroup},
+		{value: struct{}{}, kind: errstack.KindString},
+	}
+	for _, tc := range testCases {
+		if got, want := errstack.NewField("l", tc.value).Kind, tc.kind; got != want {
+			t.Errorf("unmatch kind for %T, got:%v, want:%v", tc.value, got, want)
+		}
+	}
+}
+
+func TestFieldJSON(t *testing.T) {
+	fields := []errstack.Field{
+		errstack.NewField("s", "x"),
+		errstack.NewField("i", -12),
+		errstack.NewField("u", uint64(12)),
+		errstack.NewField("f", float32(1.2)),
+		errstack.NewField("b", true),
+		errstack.NewField("t", time.Date(2019, 10, 22, 5, 31, 53, 0, time.UTC)),
+		errstack.NewField("d", time.Second),
+		errstack.NewField("x", []byte{'\xba'}),
+		errstack.NewField("g", []errstack.Field{errstack.NewField("a", 1)}),
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"label":"s","kind":"string","value":"x"},` +
+		`{"label":"i","kind":"int64","value":-12},` +
+		`{"label":"u","kind":"uint64","value":12},` +
+		`{"label":"f","kind":"float64","value":1.2},` +
+		`{"label":"b","kind":"bool","value":true},` +
+		`{"label":"t","kind":"time","value":"2019-10-22T05:31:53Z"},` +
+		`{"label":"d","kind":"duration","value":"1s"},` +
+		`{"label":"x","kind":"bytes","value":"ba"},` +
+		`{"label":"g","kind":"group","value":[{"label":"a","kind":"int64","value":1}]}]`
+	if got := string(data); got != want {
+		t.Errorf("unmatch result,\n got:%s,\nwant:%s", got, want)
+	}
+
+	var got []errstack.Field
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	for i := range fields {
+		if got, want := got[i].Kind, fields[i].Kind; got != want {
+			t.Errorf("unmatch kind of %s, got:%v, want:%v", fields[i].Label, got, want)
+		}
+		if got, want := got[i].String(), fields[i].String(); got != want {
+			t.Errorf("unmatch string of %s, got:%s, want:%s", fields[i].Label, got, want)
+		}
+	}
+}
+
+func TestFieldJSONFloatSpecial(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value float64
+		want  string
+	}{
+		{name: "nan", value: math.NaN(), want: `{"label":"f","kind":"float64","value":"NaN"}`},
+		{name: "posInf", value: math.Inf(1), want: `{"label":"f","kind":"float64","value":"+Inf"}`},
+		{name: "negInf", value: math.Inf(-1), want: `{"label":"f","kind":"float64","value":"-Inf"}`},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(errstack.NewField("f", tc.value))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(data); got != tc.want {
+				t.Errorf("unmatch result, got:%s, want:%s", got, tc.want)
+			}
+
+			var f errstack.Field
+			if err := json.Unmarshal(data, &f); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := f.Kind, errstack.KindFloat64; got != want {
+				t.Errorf("unmatch kind, got:%v, want:%v", got, want)
+			}
+			got, ok := f.Value().(float64)
+			if !ok {
+				t.Fatalf("unmatch value type, got:%T, want:float64", f.Value())
+			}
+			if math.IsNaN(tc.value) {
+				if !math.IsNaN(got) {
+					t.Errorf("unmatch value, got:%v, want:NaN", got)
+				}
+			} else if got != tc.value {
+				t.Errorf("unmatch value, got:%v, want:%v", got, tc.value)
+			}
+		})
+	}
+}
+
+func TestFieldLiteral(t *testing.T) {
+	kinds := []errstack.Kind{errstack.KindTime, errstack.KindBytes, errstack.KindGroup}
+	for _, kind := range kinds {
+		f := errstack.Field{Label: "a", Kind: kind}
+		err := errstack.WithLV(errors.New("my error")).Group("g", f)
+		if got, want := f.String(), ""; got != want {
+			t.Errorf("unmatch string of %v, got:%q, want:%q", kind, got, want)
+		}
+		if got, want := errstack.LV(err), []string{"g", "{a=}"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("lv unmatch for %v, got:%v, want:%v", kind, got, want)
+		}
+		if _, err := json.Marshal(errstack.Fields(err)); err != nil {
+			t.Errorf("unexpected error for %v: %v", kind, err)
+		}
+		_ = fmt.Sprintf("%+v", err)
+	}
+}
+
+func TestFieldValueCopy(t *testing.T) {
+	err := errstack.WithLV(errors.New("my error")).
+		HexBytes("bytes", []byte{1, 2}).
+		Group("group", errstack.NewField("a", 1))
+	fields := errstack.Fields(err)
+	fields[0].Value().([]byte)[0] = 0xff
+	fields[1].Value().([]errstack.Field)[0] = errstack.NewField("b", 2)
+	if got, want := errstack.LV(err), []string{"bytes", "0102", "group", "{a=1}"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+	}
+}
@@ -0,0 +1,102 @@
+package errstack
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// FrameFilter reports whether a stack call frame should be kept.
+type FrameFilter func(f Frame) bool
+
+type frameFilterHolder struct {
+	filter FrameFilter
+}
+
+var frameFilter atomic.Value
+
+// SetFrameFilter sets the filter of stack call frames. Frames for which
+// filter returns false are dropped when the frames are resolved, and when
+// they are printed with the verb %+v, so that frames obtained with the
+// Stack() []Frame methods of other types are filtered as well.
+//
+// The filter is also applied to errors created before SetFrameFilter is
+// called whose frames have not been obtained yet.
+// Passing nil removes the filter, which is the default.
+func SetFrameFilter(filter FrameFilter) {
+	frameFilter.Store(frameFilterHolder{filter: filter})
+}
+
+func getFrameFilter() FrameFilter {
+	h, _ := frameFilter.Load().(frameFilterHolder)
+	return h.filter
+}
+
+// ExcludeRuntime is a FrameFilter which drops frames of the runtime
+// package and its internal packages such as runtime.goexit and
+// runtime.main.
+func ExcludeRuntime(f Frame) bool {
+	pkg := f.Package()
+	return pkg != "runtime" && !strings.HasPrefix(pkg, "runtime/internal/") &&
+		!strings.HasPrefix(pkg, "internal/runtime/")
+}
+
+// ExcludeTesting is a FrameFilter which drops frames of the testing
+// package such as testing.tRunner.
+func ExcludeTesting(f Frame) bool {
+	return f.Package() != "testing"
+}
+
+// ExcludePackages returns a FrameFilter which drops frames of the packages
+// whose import paths are one of prefixes or start with one of prefixes
+// followed by a slash.
+func ExcludePackages(prefixes ...string) FrameFilter {
+	prefixes = append([]string(nil), prefixes...)
+	return func(f Frame) bool {
+		pkg := f.Package()
+		for _, p := range prefixes {
+			p = strings.TrimSuffix(p, "/")
+			if pkg == p || strings.HasPrefix(pkg, p+"/") {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// CombineFilters returns a FrameFilter which keeps frames which all of
+// filters keep.
+func CombineFilters(filters ...FrameFilter) FrameFilter {
+	filters = append([]FrameFilter(nil), filters...)
+	return func(f Frame) bool {
+		for _, filter := range filters {
+			if filter != nil && !filter(f) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// filterFrames returns frames which the filter set with SetFrameFilter
+// keeps. It returns frames as is if no filter is set or the filter keeps
+// all of them.
+func filterFrames(frames []Frame) []Frame {
+	filter := getFrameFilter()
+	if filter == nil {
+		return frames
+	}
+	for i, f := range frames {
+		if filter(f) {
+			continue
+		}
+		kept := make([]Frame, i, len(frames))
+		copy(kept, frames[:i])
+		for _, f2 := range frames[i+1:] {
+			if filter(f2) {
+				kept = append(kept, f2)
+			}
+		}
+		return kept
+	}
+	return frames
+}
@@ -0,0 +1,119 @@
+package errstack_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestSetFrameFilter(t *testing.T) {
+	defer errstack.SetFrameFilter(nil)
+
+	t.Run("capture", func(t *testing.T) {
+		errstack.SetFrameFilter(errstack.CombineFilters(errstack.ExcludeRuntime, errstack.ExcludeTesting))
+		defer errstack.SetFrameFilter(nil)
+
+		err := testFilterLevel1()
+		want := []string{
+			"github.com/hnakamur/errstack_test.testFilterLevel1",
+			"github.com/hnakamur/errstack_test.TestSetFrameFilter.func1",
+		}
+		frames := errstack.Stack(err)
+		if got, want := len(frames), len(want); got != want {
+			t.Fatalf("unmatch frame count, got:%d, want:%d, frames:%v", got, want, frames)
+		}
+		testStackFrameNames(t, frames, want)
+	})
+	t.Run("print", func(t *testing.T) {
+		err := fmt.Errorf("outer: %w", testFilterStackError{})
+		errstack.SetFrameFilter(errstack.ExcludePackages("example.com/middleware"))
+		defer errstack.SetFrameFilter(nil)
+
+		got := fmt.Sprintf("%+v", errstack.Errorf("top: %w", err))
+		want := "top: outer: my error\n" +
+			"example.com/app.handle\n" +
+			"\t/app/handle.go:12"
+		if got != want {
+			t.Errorf("unmatch result,\n got:%s,\nwant:%s", got, want)
+		}
+	})
+	t.Run("panic", func(t *testing.T) {
+		errstack.SetFrameFilter(errstack.ExcludeRuntime)
+		defer errstack.SetFrameFilter(nil)
+
+		err := testFilterPanic()
+		testStackFrameNames(t, errstack.Stack(err), []string{
+			"github.com/hnakamur/errstack_test.testFilterPanicLevel1",
+			"github.com/hnakamur/errstack_test.testFilterPanic",
+		})
+	})
+}
+
+func TestExcludePackages(t *testing.T) {
+	filter := errstack.ExcludePackages("example.com/middleware", "example.com/lib/")
+	testCases := []struct {
+		name string
+		keep bool
+	}{
+		{name: "example.com/middleware.Wrap.func1", keep: false},
+		{name: "example.com/middleware/auth.(*Handler).ServeHTTP", keep: false},
+		{name: "example.com/middlewarex.F", keep: true},
+		{name: "example.com/lib.F", keep: false},
+		{name: "example.com/app.F", keep: true},
+		{name: "vendor/example.com/middleware.F", keep: false},
+	}
+	for _, tc := range testCases {
+		if got, want := filter(errstack.Frame{Name: tc.name}), tc.keep; got != want {
+			t.Errorf("unmatch result for %s, got:%v, want:%v", tc.name, got, want)
+		}
+	}
+}
+
+func TestExcludeRuntimeAndTesting(t *testing.T) {
+	testCases := []struct {
+		filter errstack.FrameFilter
+		name   string
+		keep   bool
+	}{
+		{filter: errstack.ExcludeRuntime, name: "runtime.goexit", keep: false},
+		{filter: errstack.ExcludeRuntime, name: "runtime.main", keep: false},
+		{filter: errstack.ExcludeRuntime, name: "runtime/debug.Stack", keep: true},
+		{filter: errstack.ExcludeRuntime, name: "testing.tRunner", keep: true},
+		{filter: errstack.ExcludeTesting, name: "testing.tRunner", keep: false},
+		{filter: errstack.ExcludeTesting, name: "main.main", keep: true},
+	}
+	for _, tc := range testCases {
+		if got, want := tc.filter(errstack.Frame{Name: tc.name}), tc.keep; got != want {
+			t.Errorf("unmatch result for %s, got:%v, want:%v", tc.name, got, want)
+		}
+	}
+}
+
+type testFilterStackError struct{}
+
+func (testFilterStackError) Error() string { return "my error" }
+
+func (testFilterStackError) Stack() []errstack.Frame {
+	return []errstack.Frame{
+		{Name: "example.com/middleware.Wrap.func1", Line: 34, Path: "/middleware/wrap.go"},
+		{Name: "example.com/app.handle", Line: 12, Path: "/app/handle.go"},
+		{Name: "example.com/middleware.Recover.func1", Line: 56, Path: "/middleware/recover.go"},
+	}
+}
+
+func testFilterLevel1() error {
+	return errstack.Errorf("level1: %w", os.ErrNotExist)
+}
+
+func testFilterPanic() (err error) {
+	defer errstack.Recover(&err)
+	testFilterPanicLevel1()
+	return nil
+}
+
+func testFilterPanicLevel1() {
+	var m map[string]int
+	m["a"] = 1
+}
@@ -0,0 +1,72 @@
+package errstack
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Fingerprint returns a hash of err which can be used to group
+// identical failures.
+//
+// The hash is computed from the function names of the stack call
+// frames returned by Stack and the type of the innermost error
+// in err's chain formatted with "%T". Frames of functions in the
+// runtime package are ignored. Since file paths are not used, the hash
+// is the same for builds on different machines or in different
+// GOPATH or module cache locations, and it changes when the failing
+// call path changes.
+//
+// Fingerprint returns an empty string if err is nil.
+func Fingerprint(err error) string {
+	return fingerprint(err, false)
+}
+
+// FingerprintWithLines is the same as Fingerprint except that the
+// base names of files and the line numbers of the stack call frames
+// are also used to compute the hash.
+func FingerprintWithLines(err error) string {
+	return fingerprint(err, true)
+}
+
+func fingerprint(err error, withLines bool) string {
+	if err == nil {
+		return ""
+	}
+
+	h := fnv.New64a()
+	for _, f := range Stack(err) {
+		if strings.HasPrefix(f.Name, "runtime.") {
+			continue
+		}
+		h.Write([]byte(f.Name))
+		if withLines {
+			h.Write([]byte{'@'})
+			h.Write([]byte(path.Base(strings.Replace(f.Path, "\\", "/", -1))))
+			h.Write([]byte{':'})
+			h.Write([]byte(strconv.Itoa(f.Line)))
+		}
+		h.Write([]byte{'\n'})
+	}
+	h.Write([]byte(fmt.Sprintf("%T", innermost(err))))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// innermost returns the last error in err's chain following
+// Unwrap() error methods.
+func innermost(err error) error {
+	for {
+		e2, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return err
+		}
+		next := e2.Unwrap()
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
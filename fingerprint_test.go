@@ -0,0 +1,100 @@
+package errstack_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestFingerprint(t *testing.T) {
+	t.Run("stable", func(t *testing.T) {
+		var errs [2]error
+		for i := range errs {
+			errs[i] = testFingerprintLevel1()
+		}
+		err1, err2 := errs[0], errs[1]
+		if got, want := errstack.Fingerprint(err1), errstack.Fingerprint(err2); got != want {
+			t.Errorf("unmatch fingerprint, got:%s, want:%s", got, want)
+		}
+		if got, want := errstack.FingerprintWithLines(err1), errstack.FingerprintWithLines(err2); got != want {
+			t.Errorf("unmatch fingerprint with lines, got:%s, want:%s", got, want)
+		}
+	})
+	t.Run("callPathChanged", func(t *testing.T) {
+		err1 := testFingerprintLevel1()
+		err2 := testFingerprintLevel2()
+		if errstack.Fingerprint(err1) == errstack.Fingerprint(err2) {
+			t.Errorf("fingerprint must differ for different call paths")
+		}
+	})
+	t.Run("errorTypeChanged", func(t *testing.T) {
+		err1 := errstack.Errorf("level1: %w", os.ErrNotExist)
+		err2 := errstack.Errorf("level1: %w", context.DeadlineExceeded)
+		if errstack.Fingerprint(err1) == errstack.Fingerprint(err2) {
+			t.Errorf("fingerprint must differ for different error types")
+		}
+	})
+	t.Run("pathNormalized", func(t *testing.T) {
+		testCases := []struct {
+			prefix1 string
+			prefix2 string
+		}{
+			{prefix1: "/home/alice/go/src/github.com/hnakamur/errstack/", prefix2: "/build/src/github.com/hnakamur/errstack/"},
+			{prefix1: "/root/go/pkg/mod/example.com/lib@v1.2.3/", prefix2: "C:\\Users\\bob\\go\\pkg\\mod\\example.com\\lib@v1.2.3\\"},
+		}
+		for _, tc := range testCases {
+			err1 := fmt.Errorf("outer: %w", testFingerprintStackError{prefix: tc.prefix1})
+			err2 := fmt.Errorf("outer: %w", testFingerprintStackError{prefix: tc.prefix2})
+			if got, want := errstack.Fingerprint(err1), errstack.Fingerprint(err2); got != want {
+				t.Errorf("unmatch fingerprint, got:%s, want:%s", got, want)
+			}
+			if got, want := errstack.FingerprintWithLines(err1), errstack.FingerprintWithLines(err2); got != want {
+				t.Errorf("unmatch fingerprint with lines, got:%s, want:%s", got, want)
+			}
+		}
+	})
+	t.Run("lineChanged", func(t *testing.T) {
+		err1 := testFingerprintStackError{prefix: "/a/"}
+		err2 := testFingerprintStackError{prefix: "/a/", lineOffset: 1}
+		if got, want := errstack.Fingerprint(err1), errstack.Fingerprint(err2); got != want {
+			t.Errorf("unmatch fingerprint, got:%s, want:%s", got, want)
+		}
+		if errstack.FingerprintWithLines(err1) == errstack.FingerprintWithLines(err2) {
+			t.Errorf("fingerprint with lines must differ for different lines")
+		}
+	})
+	t.Run("nil", func(t *testing.T) {
+		if got, want := errstack.Fingerprint(nil), ""; got != want {
+			t.Errorf("unmatch fingerprint, got:%s, want:%s", got, want)
+		}
+	})
+}
+
+type testFingerprintStackError struct {
+	prefix     string
+	lineOffset int
+}
+
+func (e testFingerprintStackError) Error() string { return "my error" }
+
+func (e testFingerprintStackError) Stack() []errstack.Frame {
+	sep := "/"
+	if len(e.prefix) > 0 && e.prefix[len(e.prefix)-1] == '\\' {
+		sep = "\\"
+	}
+	return []errstack.Frame{
+		{Name: "example.com/lib.level2", Line: 12 + e.lineOffset, Path: e.prefix + "lib" + sep + "level2.go"},
+		{Name: "example.com/lib.level1", Line: 34, Path: e.prefix + "level1.go"},
+	}
+}
+
+func testFingerprintLevel1() error {
+	return testFingerprintLevel2()
+}
+
+func testFingerprintLevel2() error {
+	return errstack.New("my error")
+}
@@ -0,0 +1,203 @@
+package errstack
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// Format implements fmt.Formatter.
+//
+// The verbs other than %+v format the error message in the same
+// way as an error without the Format method, including flags,
+// width and precision.
+// The verb %+v prints the error message, the pairs of labels
+// and values, and the call stack frames, one frame per line
+// in the "function\n\tfile:line" layout. If wrap points are
+// recorded, the result of Trace follows under the
+// "error return trace:" line. If the goroutine is recorded,
+// the frames are surrounded by the "goroutine N [running]:" line
+// and the "created by" frame as in the traceback of the Go runtime.
+//
+// A precision like %+.3v prints the source lines of code within that
+// many lines around the line of each frame. The current line is marked
+// with ">". Files which are missing or unreadable are skipped.
+func (e *errorWithStack) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e)
+}
+
+// Format implements fmt.Formatter.
+// See (*errorWithStack).Format for the supported verbs.
+func (e *errorWithLV) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e)
+}
+
+// Format implements fmt.Formatter.
+// See (*errorWithStack).Format for the supported verbs.
+func (e *RemoteError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e)
+}
+
+// Format implements fmt.Formatter.
+// See (*errorWithStack).Format for the supported verbs.
+func (e *redactedError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e)
+}
+
+// Format implements fmt.Formatter.
+// See (*errorWithStack).Format for the supported verbs.
+func (e *joinError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e)
+}
+
+func formatError(s fmt.State, verb rune, err error) {
+	if verb == 'v' && s.Flag('+') {
+		var b bytes.Buffer
+		source, _ := s.Precision()
+		writeVerbose(&b, err, source)
+		s.Write(b.Bytes())
+		return
+	}
+	fmt.Fprintf(s, formatDirective(s, verb), err.Error())
+}
+
+// formatDirective rebuilds the directive like "%-8s" from s and verb,
+// so that flags, width and precision are applied to the error message
+// in the same way as an error without the Format method.
+func formatDirective(s fmt.State, verb rune) string {
+	b := []byte{'%'}
+	for _, flag := range "+-# 0" {
+		if s.Flag(int(flag)) {
+			b = append(b, byte(flag))
+		}
+	}
+	if width, ok := s.Width(); ok {
+		b = strconv.AppendInt(b, int64(width), 10)
+	}
+	if prec, ok := s.Precision(); ok {
+		b = append(b, '.')
+		b = strconv.AppendInt(b, int64(prec), 10)
+	}
+	return string(append(b, string(verb)...))
+}
+
+// writeVerbose writes the error message, the pairs of labels and values,
+// and the call stack frames of err to b.
+//
+// If err wraps an error returned by Join, the message is replaced with
+// the count of the joined errors unless err adds its own message, only
+// the pairs and the frames of the errors above the joined errors are
+// written, and the verbose output of each joined error follows them.
+//
+// If source is positive, source lines of code around the line of each
+// frame are written after the frame.
+func writeVerbose(b *bytes.Buffer, err error, source int) {
+	j := findJoin(err)
+	if j == nil {
+		b.WriteString(err.Error())
+		writeLV(b, LV(err))
+		g := stackGoroutine(err)
+		writeGoroutineHeader(b, g)
+		writeFrames(b, Stack(err), source)
+		writeGoroutineCreatedBy(b, g)
+		writeCreated(b, err, source)
+		writeTrace(b, Trace(err))
+		return
+	}
+
+	b.WriteString(joinHeader(err, j))
+	writeLV(b, fieldsLV(redactFields(inheritedFields(err))))
+	g := chainGoroutine(err)
+	writeGoroutineHeader(b, g)
+	writeFrames(b, chainStack(err), source)
+	writeGoroutineCreatedBy(b, g)
+	writeTrace(b, chainTrace(err))
+	writeJoinVerbose(b, j, source)
+}
+
+// joinHeader returns the message of err which wraps j, or the count of
+// the joined errors if err does not add its own message.
+func joinHeader(err error, j *joinError) string {
+	if msg := err.Error(); msg != j.Error() {
+		return msg
+	}
+	if len(j.errs) == 1 {
+		return "1 error occurred:"
+	}
+	return strconv.Itoa(len(j.errs)) + " errors occurred:"
+}
+
+// chainStack returns the stack call frames of the first error in err's
+// chain which has them. Unlike Stack, it does not descend into the errors
+// joined with Join or any other multi-errors.
+func chainStack(err error) []Frame {
+	for err != nil {
+		if e2, ok := err.(interface{ Stack() []Frame }); ok {
+			if s := e2.Stack(); s != nil {
+				return s
+			}
+		}
+		e2, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return nil
+		}
+		err = e2.Unwrap()
+	}
+	return nil
+}
+
+// writeCreated writes the call stack frames where the goroutine which
+// returned err was started by Group.Go, if err's chain has them.
+func writeCreated(b *bytes.Buffer, err error, source int) {
+	for err != nil {
+		if e2, ok := err.(interface{ createdStack() []Frame }); ok {
+			b.WriteString("\ngoroutine started at:")
+			writeFrames(b, e2.createdStack(), source)
+			return
+		}
+		e2, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return
+		}
+		err = e2.Unwrap()
+	}
+}
+
+// findJoin returns the first joinError in err's chain.
+// It does not descend into other multi-errors.
+func findJoin(err error) *joinError {
+	for err != nil {
+		if j, ok := err.(*joinError); ok {
+			return j
+		}
+		e2, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return nil
+		}
+		err = e2.Unwrap()
+	}
+	return nil
+}
+
+func writeLV(b *bytes.Buffer, lv []string) {
+	for i := 0; i+1 < len(lv); i += 2 {
+		b.WriteByte('\n')
+		b.WriteString(lv[i])
+		b.WriteByte('=')
+		b.WriteString(lv[i+1])
+	}
+}
+
+func writeFrames(b *bytes.Buffer, frames []Frame, source int) {
+	for _, f := range filterFrames(frames) {
+		b.WriteByte('\n')
+		b.WriteString(f.Name)
+		b.WriteString("\n\t")
+		b.WriteString(f.Path)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(f.Line))
+		if source > 0 {
+			writeSource(b, &f, source)
+		}
+	}
+}
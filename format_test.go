@@ -0,0 +1,84 @@
+package errstack_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestFormat(t *testing.T) {
+	t.Run("errorWithStack", func(t *testing.T) {
+		err := errstack.New("my error")
+		testFormatSimple(t, err, "my error")
+	})
+	t.Run("errorWithLV", func(t *testing.T) {
+		err := errstack.WithLV(errstack.New("my error"), "reqID", "req1")
+		testFormatSimple(t, err, "my error")
+	})
+	t.Run("plusV", func(t *testing.T) {
+		err := errstack.WithLV(testFormatLevel1(), "reqID", "req1").Int64("userID", 1)
+		got := fmt.Sprintf("%+v", err)
+		lines := strings.Split(got, "\n")
+		if len(lines) < 5 {
+			t.Fatalf("too few lines, got:%q", got)
+		}
+		want := []string{
+			"my error",
+			"reqID=req1",
+			"userID=1",
+			"github.com/hnakamur/errstack_test.testFormatLevel1",
+		}
+		for i, w := range want {
+			if lines[i] != w {
+				t.Errorf("unmatch line %d, got:%q, want:%q", i, lines[i], w)
+			}
+		}
+		if !strings.HasPrefix(lines[4], "\t") || !strings.Contains(lines[4], "format_test.go:") {
+			t.Errorf("unmatch file line, got:%q", lines[4])
+		}
+	})
+	t.Run("plusVWrappedWithFmtErrorf", func(t *testing.T) {
+		err := fmt.Errorf("outer: %w", testFormatLevel1())
+		if got, want := fmt.Sprintf("%+v", err), "outer: my error"; got != want {
+			t.Errorf("unmatch result, got:%q, want:%q", got, want)
+		}
+		got := fmt.Sprintf("%+v", errors.Unwrap(err))
+		if !strings.HasPrefix(got, "my error\ngithub.com/hnakamur/errstack_test.testFormatLevel1\n\t") {
+			t.Errorf("unmatch result, got:%q", got)
+		}
+	})
+}
+
+func TestFormatDirectives(t *testing.T) {
+	errs := []error{
+		errstack.New("boom"),
+		errstack.WithLV(errstack.New("boom"), "reqID", "req1"),
+		errstack.Join(errors.New("boom")),
+	}
+	for _, format := range []string{"%v", "%s", "%q", "%-8s", "%8v", "%.2s", "%x", "%X", "% x", "%#q", "[%6.3v]"} {
+		want := fmt.Sprintf(format, errors.New("boom"))
+		for _, err := range errs {
+			if got := fmt.Sprintf(format, err); got != want {
+				t.Errorf("unmatch %s result for %T, got:%q, want:%q", format, err, got, want)
+			}
+		}
+	}
+}
+
+func testFormatLevel1() error { return errstack.New("my error") }
+
+func testFormatSimple(t *testing.T, err error, msg string) {
+	t.Helper()
+	if got, want := fmt.Sprintf("%v", err), msg; got != want {
+		t.Errorf("unmatch %%v result, got:%q, want:%q", got, want)
+	}
+	if got, want := fmt.Sprintf("%s", err), msg; got != want {
+		t.Errorf("unmatch %%s result, got:%q, want:%q", got, want)
+	}
+	if got, want := fmt.Sprintf("%q", err), `"`+msg+`"`; got != want {
+		t.Errorf("unmatch %%q result, got:%q, want:%q", got, want)
+	}
+}
@@ -0,0 +1,173 @@
+package errstack
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Package returns the import path of the package of the function.
+// The vendor directory prefix such as "vendor/" or "example.com/app/vendor/"
+// is removed, and characters escaped by the linker, such as dots in the
+// last element like "gopkg.in/yaml%2ev3", are unescaped.
+//
+// For example, it returns "github.com/x/y" for "github.com/x/y.(*T).M.func1".
+func (f *Frame) Package() string {
+	pkg, _ := splitFuncName(f.Name)
+	if pkg2, err := url.PathUnescape(pkg); err == nil {
+		pkg = pkg2
+	}
+	if i := strings.LastIndex(pkg, "/vendor/"); i >= 0 {
+		pkg = pkg[i+len("/vendor/"):]
+	} else if strings.HasPrefix(pkg, "vendor/") {
+		pkg = pkg[len("vendor/"):]
+	}
+	return pkg
+}
+
+// Receiver returns the receiver type of the method, or an empty string
+// if the function is not a method. The type parameters of a generic type
+// are removed.
+//
+// For example, it returns "*T" for "github.com/x/y.(*T).M.func1",
+// "T" for "github.com/x/y.T.M", and "*List" for
+// "github.com/x/y.(*List[...]).Push".
+func (f *Frame) Receiver() string {
+	recv, _, _ := parseFuncName(f.Name)
+	return recv
+}
+
+// FuncName returns the name of the function or the method without the
+// package, the receiver type, the type parameters and the suffixes of
+// closures.
+//
+// For example, it returns "M" for "github.com/x/y.(*T).M.func1",
+// and "Map" for "github.com/x/y.Map[...].func2.1".
+func (f *Frame) FuncName() string {
+	_, name, _ := parseFuncName(f.Name)
+	return name
+}
+
+// IsClosure reports whether the function is a function literal,
+// including nested ones, such as "github.com/x/y.F.func1.2".
+func (f *Frame) IsClosure() bool {
+	_, _, closure := parseFuncName(f.Name)
+	return closure
+}
+
+// splitFuncName splits a function name returned by runtime.Frame.Function
+// into the package path and the rest. Slashes and dots inside brackets and
+// parentheses are ignored.
+func splitFuncName(name string) (pkg, rest string) {
+	lastSlash := -1
+	depth := 0
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		case '/':
+			if depth == 0 {
+				lastSlash = i
+			}
+		}
+	}
+	depth = 0
+	for i := lastSlash + 1; i < len(name); i++ {
+		switch name[i] {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		case '.':
+			if depth == 0 {
+				return name[:i], name[i+1:]
+			}
+		}
+	}
+	return "", name
+}
+
+func parseFuncName(name string) (recv, fn string, closure bool) {
+	_, rest := splitFuncName(name)
+	parts := splitOutsideBrackets(rest, '.')
+	if len(parts) == 0 {
+		return "", "", false
+	}
+
+	i := 0
+	switch {
+	case strings.HasPrefix(parts[0], "("):
+		recv = strings.TrimSuffix(strings.TrimPrefix(parts[0], "("), ")")
+		i = 1
+	case len(parts) > 1 && parts[1] != "" && !isClosureName(parts[1]):
+		recv = parts[0]
+		i = 1
+	}
+	if i < len(parts) {
+		fn = parts[i]
+	}
+	// Package initializers are named "init.0", "init.1" and so on,
+	// where the numeric part is not a closure.
+	if recv == "" && fn == "init" && len(parts) > i+1 && isDigits(parts[i+1]) {
+		i++
+	}
+	return trimTypeParams(recv), trimTypeParams(fn), len(parts) > i+1
+}
+
+// splitOutsideBrackets splits s by sep which are not inside brackets
+// or parentheses.
+func splitOutsideBrackets(s string, sep byte) []string {
+	if s == "" {
+		return nil
+	}
+	var parts []string
+	depth := 0
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// isClosureName reports whether s is a name which the compiler gives to
+// function literals, such as "func1", "1", "gowrap1" or "deferwrap1".
+func isClosureName(s string) bool {
+	for _, prefix := range []string{"func", "gowrap", "deferwrap"} {
+		if strings.HasPrefix(s, prefix) {
+			s = s[len(prefix):]
+			break
+		}
+	}
+	return isDigits(s)
+}
+
+// isDigits reports whether s is a non-empty string of decimal digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func trimTypeParams(s string) string {
+	if i := strings.IndexByte(s, '['); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
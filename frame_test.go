@@ -0,0 +1,74 @@
+package errstack_test
+
+import (
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestFrameFuncName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pkg      string
+		recv     string
+		funcName string
+		closure  bool
+	}{
+		{name: "github.com/x/y.F", pkg: "github.com/x/y", funcName: "F"},
+		{name: "github.com/x/y.(*T).M", pkg: "github.com/x/y", recv: "*T", funcName: "M"},
+		{name: "github.com/x/y.T.M", pkg: "github.com/x/y", recv: "T", funcName: "M"},
+		{name: "github.com/x/y.(*T).M.func1", pkg: "github.com/x/y", recv: "*T", funcName: "M", closure: true},
+		{name: "github.com/x/y.F.func1.2", pkg: "github.com/x/y", funcName: "F", closure: true},
+		{name: "github.com/x/y.F.gowrap1", pkg: "github.com/x/y", funcName: "F", closure: true},
+		{name: "github.com/x/y.Map[...]", pkg: "github.com/x/y", funcName: "Map"},
+		{name: "github.com/x/y.Map[...].func2.1", pkg: "github.com/x/y", funcName: "Map", closure: true},
+		{name: "github.com/x/y.(*List[...]).Push", pkg: "github.com/x/y", recv: "*List", funcName: "Push"},
+		{name: "github.com/x/y.List[...].Len", pkg: "github.com/x/y", recv: "List", funcName: "Len"},
+		{name: "github.com/x/y.Map[go.shape.struct { a/b.T }]", pkg: "github.com/x/y", funcName: "Map"},
+		{name: "github.com/x/y.glob..func1", pkg: "github.com/x/y", funcName: "glob", closure: true},
+		{name: "github.com/x/y.init.0", pkg: "github.com/x/y", funcName: "init"},
+		{name: "github.com/x/y.init.0.func1", pkg: "github.com/x/y", funcName: "init", closure: true},
+		{name: "gopkg.in/yaml%2ev3.(*parser).parse", pkg: "gopkg.in/yaml.v3", recv: "*parser", funcName: "parse"},
+		{name: "vendor/golang.org/x/net/http2.(*Framer).WriteData", pkg: "golang.org/x/net/http2", recv: "*Framer", funcName: "WriteData"},
+		{name: "example.com/app/vendor/github.com/x/y.F", pkg: "github.com/x/y", funcName: "F"},
+		{name: "main.main", pkg: "main", funcName: "main"},
+		{name: "runtime.goexit", pkg: "runtime", funcName: "goexit"},
+	}
+	for _, tc := range testCases {
+		f := errstack.Frame{Name: tc.name}
+		if got, want := f.Package(), tc.pkg; got != want {
+			t.Errorf("unmatch package for %s, got:%s, want:%s", tc.name, got, want)
+		}
+		if got, want := f.Receiver(), tc.recv; got != want {
+			t.Errorf("unmatch receiver for %s, got:%s, want:%s", tc.name, got, want)
+		}
+		if got, want := f.FuncName(), tc.funcName; got != want {
+			t.Errorf("unmatch func name for %s, got:%s, want:%s", tc.name, got, want)
+		}
+		if got, want := f.IsClosure(), tc.closure; got != want {
+			t.Errorf("unmatch closure for %s, got:%v, want:%v", tc.name, got, want)
+		}
+	}
+}
+
+func TestFramePC(t *testing.T) {
+	frames := errstack.Stack(testFramePCLevel1())
+	if len(frames) == 0 {
+		t.Fatal("no frames")
+	}
+	f := frames[0]
+	if got, want := f.Name, "github.com/hnakamur/errstack_test.testFramePCLevel1"; got != want {
+		t.Fatalf("unmatch name, got:%s, want:%s", got, want)
+	}
+	if f.Inlined {
+		t.Errorf("testFramePCLevel1 must not be inlined")
+	}
+	if f.PC == 0 || f.Entry == 0 || f.PC < f.Entry {
+		t.Errorf("invalid pc, got PC:%#x, Entry:%#x", f.PC, f.Entry)
+	}
+}
+
+//go:noinline
+func testFramePCLevel1() error {
+	return errstack.New("my error")
+}
@@ -0,0 +1,203 @@
+package errstack
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// goroutineInfo is the identity of the goroutine where call stack
+// frames were recorded.
+type goroutineInfo struct {
+	id uint64
+	// createdBy holds the untrimmed path so that the path options
+	// are applied when it is read.
+	createdBy *Frame
+	parentID  uint64
+}
+
+var goroutineRecording uint32
+
+// SetGoroutineRecording enables or disables recording of the goroutine
+// ID and the frame where the goroutine was created when call stack frames
+// are generated. They are parsed from the output of runtime.Stack,
+// which is much more expensive than recording call stack frames.
+//
+// The recorded values can be obtained with GoroutineID and CreatedBy,
+// and they are printed with the verb %+v in the same way as the traceback
+// of the Go runtime.
+//
+// Recording is disabled by default.
+func SetGoroutineRecording(enabled bool) {
+	var v uint32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreUint32(&goroutineRecording, v)
+}
+
+// currentGoroutine returns the identity of the current goroutine,
+// or nil if recording goroutines is disabled.
+func currentGoroutine() *goroutineInfo {
+	if atomic.LoadUint32(&goroutineRecording) == 0 {
+		return nil
+	}
+	buf := make([]byte, 4096)
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) || len(buf) >= 1<<20 {
+			return parseGoroutine(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// parseGoroutine parses the output of runtime.Stack for a goroutine like:
+//
+//	goroutine 7 [running]:
+//	main.main.func1()
+//		/tmp/main.go:3 +0x45
+//	created by main.main in goroutine 1
+//		/tmp/main.go:3 +0x76
+func parseGoroutine(buf []byte) *goroutineInfo {
+	const header = "goroutine "
+	if !bytes.HasPrefix(buf, []byte(header)) {
+		return nil
+	}
+	line := buf[len(header):]
+	i := bytes.IndexByte(line, ' ')
+	if i < 0 {
+		return nil
+	}
+	id, err := strconv.ParseUint(string(line[:i]), 10, 64)
+	if err != nil {
+		return nil
+	}
+	g := &goroutineInfo{id: id}
+
+	const createdBy = "\ncreated by "
+	i = bytes.LastIndex(buf, []byte(createdBy))
+	if i < 0 {
+		return g
+	}
+	lines := strings.SplitN(string(buf[i+len(createdBy):]), "\n", 3)
+	f := &Frame{Name: lines[0]}
+	if j := strings.Index(f.Name, " in goroutine "); j >= 0 {
+		g.parentID, _ = strconv.ParseUint(f.Name[j+len(" in goroutine "):], 10, 64)
+		f.Name = f.Name[:j]
+	}
+	if len(lines) > 1 {
+		loc := strings.TrimPrefix(lines[1], "\t")
+		if j := strings.LastIndex(loc, " +0x"); j >= 0 {
+			loc = loc[:j]
+		}
+		if j := strings.LastIndexByte(loc, ':'); j >= 0 {
+			f.Line, _ = strconv.Atoi(loc[j+1:])
+			loc = loc[:j]
+		}
+		f.Path = loc
+	}
+	g.createdBy = f
+	return g
+}
+
+// GoroutineID returns the ID of the goroutine where the call stack frames
+// returned by Stack were recorded. It returns 0 if the ID was not recorded.
+// See SetGoroutineRecording.
+func GoroutineID(err error) uint64 {
+	if g := stackGoroutine(err); g != nil {
+		return g.id
+	}
+	return 0
+}
+
+// CreatedBy returns the frame where the goroutine in which the call stack
+// frames returned by Stack were recorded was created. It returns nil if
+// the frame was not recorded, or the goroutine is the main goroutine.
+// See SetGoroutineRecording.
+func CreatedBy(err error) *Frame {
+	if g := stackGoroutine(err); g != nil && g.createdBy != nil {
+		f := g.createdByFrame()
+		return &f
+	}
+	return nil
+}
+
+// createdByFrame returns a copy of the created by frame whose path is
+// trimmed with the current path options.
+func (g *goroutineInfo) createdByFrame() Frame {
+	f := *g.createdBy
+	f.Path, _ = getPathOptions().trim(f.Path)
+	return f
+}
+
+// stackGoroutine returns the goroutine recorded with the stack call frames
+// returned by Stack.
+func stackGoroutine(err error) *goroutineInfo {
+	var g *goroutineInfo
+	walk(err, func(err error) bool {
+		switch e2 := err.(type) {
+		case *errorWithStack:
+			if e2.Stack() == nil {
+				return true
+			}
+			g = e2.stack.goroutine
+			return false
+		case interface{ Stack() []Frame }:
+			return e2.Stack() == nil
+		}
+		return true
+	})
+	return g
+}
+
+// chainGoroutine is the same as stackGoroutine except that it does not
+// descend into the errors joined with Join or any other multi-errors.
+func chainGoroutine(err error) *goroutineInfo {
+	for err != nil {
+		switch e2 := err.(type) {
+		case *errorWithStack:
+			if e2.Stack() != nil {
+				return e2.stack.goroutine
+			}
+		case interface{ Stack() []Frame }:
+			if e2.Stack() != nil {
+				return nil
+			}
+		}
+		e2, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return nil
+		}
+		err = e2.Unwrap()
+	}
+	return nil
+}
+
+func writeGoroutineHeader(b *bytes.Buffer, g *goroutineInfo) {
+	if g == nil {
+		return
+	}
+	b.WriteString("\ngoroutine ")
+	b.WriteString(strconv.FormatUint(g.id, 10))
+	b.WriteString(" [running]:")
+}
+
+func writeGoroutineCreatedBy(b *bytes.Buffer, g *goroutineInfo) {
+	if g == nil || g.createdBy == nil {
+		return
+	}
+	f := g.createdByFrame()
+	b.WriteString("\ncreated by ")
+	b.WriteString(f.Name)
+	if g.parentID != 0 {
+		b.WriteString(" in goroutine ")
+		b.WriteString(strconv.FormatUint(g.parentID, 10))
+	}
+	b.WriteString("\n\t")
+	b.WriteString(f.Path)
+	b.WriteByte(':')
+	b.WriteString(strconv.Itoa(f.Line))
+}
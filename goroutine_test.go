@@ -0,0 +1,104 @@
+package errstack_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestGoroutineID(t *testing.T) {
+	t.Run("enabled", func(t *testing.T) {
+		errstack.SetGoroutineRecording(true)
+		defer errstack.SetGoroutineRecording(false)
+
+		errc := make(chan error)
+		go func() {
+			errc <- testGoroutineLevel1()
+		}()
+		err := errstack.Errorf("outer: %w", <-errc)
+
+		if got := errstack.GoroutineID(err); got == 0 {
+			t.Errorf("goroutine ID must be recorded")
+		}
+		f := errstack.CreatedBy(err)
+		if f == nil {
+			t.Fatal("created by frame must be recorded")
+		}
+		if got, want := f.Name, "github.com/hnakamur/errstack_test.TestGoroutineID.func1"; got != want {
+			t.Errorf("unmatch created by name, got:%s, want:%s", got, want)
+		}
+		if got, want := f.Path, "goroutine_test.go"; !strings.HasSuffix(got, want) {
+			t.Errorf("unmatch created by path, got:%s, want suffix:%s", got, want)
+		}
+		if f.Line == 0 {
+			t.Errorf("created by line must be recorded")
+		}
+
+		got := fmt.Sprintf("%+v", err)
+		wantHeader := fmt.Sprintf("outer: level1: file does not exist\ngoroutine %d [running]:\n"+
+			"github.com/hnakamur/errstack_test.testGoroutineLevel1\n", errstack.GoroutineID(err))
+		if !strings.HasPrefix(got, wantHeader) {
+			t.Errorf("unmatch header,\n got:%s,\nwant prefix:%s", got, wantHeader)
+		}
+		wantCreatedBy := fmt.Sprintf("\ncreated by github.com/hnakamur/errstack_test.TestGoroutineID.func1 in goroutine "+
+			"%d\n\t%s:%d", testGoroutineParentID(t), f.Path, f.Line)
+		if !strings.Contains(got, wantCreatedBy) {
+			t.Errorf("created by not found,\n got:%s,\nwant:%s", got, wantCreatedBy)
+		}
+	})
+	t.Run("trimPathAfterRecording", func(t *testing.T) {
+		errstack.SetGoroutineRecording(true)
+		defer errstack.SetGoroutineRecording(false)
+
+		errc := make(chan error)
+		go func() {
+			errc <- testGoroutineLevel1()
+		}()
+		err := <-errc
+
+		dir, err2 := os.Getwd()
+		if err2 != nil {
+			t.Fatal(err2)
+		}
+		errstack.SetPathOptions(errstack.PathOptions{TrimPrefix: dir})
+		defer errstack.SetPathOptions(errstack.PathOptions{})
+
+		f := errstack.CreatedBy(err)
+		if f == nil {
+			t.Fatal("created by frame must be recorded")
+		}
+		if got, want := f.Path, "goroutine_test.go"; got != want {
+			t.Errorf("unmatch created by path, got:%s, want:%s", got, want)
+		}
+		want := fmt.Sprintf("\ncreated by %s in goroutine %d\n\tgoroutine_test.go:%d",
+			f.Name, testGoroutineParentID(t), f.Line)
+		if got := fmt.Sprintf("%+v", err); !strings.Contains(got, want) {
+			t.Errorf("created by not found,\n got:%s,\nwant:%s", got, want)
+		}
+	})
+	t.Run("disabled", func(t *testing.T) {
+		err := testGoroutineLevel1()
+		if got, want := errstack.GoroutineID(err), uint64(0); got != want {
+			t.Errorf("unmatch goroutine ID, got:%d, want:%d", got, want)
+		}
+		if got := errstack.CreatedBy(err); got != nil {
+			t.Errorf("unmatch created by, got:%v, want:nil", got)
+		}
+		if got := fmt.Sprintf("%+v", err); strings.Contains(got, "[running]") {
+			t.Errorf("goroutine must not be printed, got:%s", got)
+		}
+	})
+}
+
+func testGoroutineLevel1() error {
+	return errstack.Errorf("level1: %w", os.ErrNotExist)
+}
+
+// testGoroutineParentID returns the ID of the current goroutine.
+// Recording goroutines must be enabled.
+func testGoroutineParentID(t *testing.T) uint64 {
+	return errstack.GoroutineID(errstack.New("parent"))
+}
@@ -0,0 +1,113 @@
+package errstack
+
+import (
+	"context"
+	"sync"
+)
+
+// Group is a collection of goroutines working on subtasks like
+// errgroup.Group in golang.org/x/sync/errgroup.
+//
+// Unlike errgroup.Group, Group keeps errors of all goroutines, records
+// the call stack frames where each goroutine was started, and converts
+// panics in goroutines into errors.
+//
+// A zero Group is valid and does not cancel on error.
+type Group struct {
+	cancel func()
+
+	wg sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error
+}
+
+type goroutineError struct {
+	err     error
+	created *stack
+}
+
+// WithContext returns a new Group and an associated Context derived from ctx.
+//
+// The derived Context is canceled the first time a function passed to Go
+// returns a non-nil error or panics, or the first time Wait returns,
+// whichever occurs first.
+func WithContext(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// Go calls the given function in a new goroutine.
+//
+// Call stack frames of the caller of Go are recorded, and they are
+// kept next to the call stack frames of the error returned by f.
+// A panic in f is converted to an error with FromPanic.
+func (g *Group) Go(f func() error) {
+	created := callers(3)
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		err := run(f)
+		if err == nil {
+			return
+		}
+		g.mu.Lock()
+		g.errs = append(g.errs, &goroutineError{err: err, created: created})
+		first := len(g.errs) == 1
+		g.mu.Unlock()
+		if first && g.cancel != nil {
+			g.cancel()
+		}
+	}()
+}
+
+func run(f func() error) (err error) {
+	defer Recover(&err)
+	return f()
+}
+
+// Wait blocks until all function calls from the Go method have returned,
+// then returns an error which joins all the non-nil errors returned from
+// them in the order they returned. It returns nil if there is no error.
+//
+// Stack and Stacks can be used to get call stack frames of the returned
+// error. The stack call frames of the caller of Go are returned from Stacks
+// following the call stack frames of each error.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel()
+	}
+	if len(g.errs) == 0 {
+		return nil
+	}
+	return &joinError{errs: g.errs}
+}
+
+func (e *goroutineError) Error() string {
+	return e.err.Error()
+}
+
+func (e *goroutineError) Unwrap() error {
+	return e.err
+}
+
+func (e *goroutineError) Stack() []Frame {
+	if s := Stack(e.err); s != nil {
+		return s
+	}
+	return e.created.Frames()
+}
+
+func (e *goroutineError) Stacks() [][]Frame {
+	ss := Stacks(e.err)
+	if s := e.created.Frames(); len(s) > 0 {
+		ss = append(ss, s)
+	}
+	return ss
+}
+
+func (e *goroutineError) createdStack() []Frame {
+	return e.created.Frames()
+}
@@ -0,0 +1,94 @@
+package errstack_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestGroup(t *testing.T) {
+	t.Run("noError", func(t *testing.T) {
+		var g errstack.Group
+		for i := 0; i < 3; i++ {
+			g.Go(func() error { return nil })
+		}
+		if err := g.Wait(); err != nil {
+			t.Errorf("unmatch result, got:%v, want:nil", err)
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		var g errstack.Group
+		testGroupGo(&g)
+		err := g.Wait()
+		if err == nil {
+			t.Fatal("unmatch result, got:nil, want:non-nil")
+		}
+		if got, want := errors.Is(err, os.ErrNotExist), true; got != want {
+			t.Errorf("unmatch Is result, got:%v, want:%v", got, want)
+		}
+		testStackFrameNames(t, errstack.Stack(err), []string{
+			"github.com/hnakamur/errstack_test.testGroupChild",
+		})
+		ss := errstack.Stacks(err)
+		if got, want := len(ss), 2; got != want {
+			t.Fatalf("unmatch stack count, got:%d, want:%d", got, want)
+		}
+		testStackFrameNames(t, ss[0], []string{
+			"github.com/hnakamur/errstack_test.testGroupChild",
+		})
+		testStackFrameNames(t, ss[1], []string{
+			"github.com/hnakamur/errstack_test.testGroupGo",
+			"github.com/hnakamur/errstack_test.TestGroup.func2",
+		})
+		got := fmt.Sprintf("%+v", err)
+		if !strings.Contains(got, "\n    goroutine started at:\n    github.com/hnakamur/errstack_test.testGroupGo\n") {
+			t.Errorf("missing goroutine start frames, got:%q", got)
+		}
+	})
+	t.Run("panic", func(t *testing.T) {
+		var g errstack.Group
+		g.Go(func() error { return nil })
+		g.Go(testGroupPanic)
+		err := g.Wait()
+		if err == nil {
+			t.Fatal("unmatch result, got:nil, want:non-nil")
+		}
+		if got, want := err.Error(), "panic: boom"; got != want {
+			t.Errorf("unmatch message, got:%q, want:%q", got, want)
+		}
+		testStackFrameNames(t, errstack.Stack(err), []string{
+			"github.com/hnakamur/errstack_test.testGroupPanic",
+		})
+	})
+	t.Run("withContext", func(t *testing.T) {
+		g, ctx := errstack.WithContext(context.Background())
+		g.Go(func() error { return errstack.New("my error") })
+		g.Go(func() error {
+			<-ctx.Done()
+			return nil
+		})
+		if err := g.Wait(); err == nil {
+			t.Fatal("unmatch result, got:nil, want:non-nil")
+		}
+		if got, want := ctx.Err(), context.Canceled; got != want {
+			t.Errorf("unmatch context error, got:%v, want:%v", got, want)
+		}
+	})
+}
+
+func testGroupGo(g *errstack.Group) {
+	g.Go(testGroupChild)
+}
+
+func testGroupChild() error {
+	return errstack.Errorf("child: %w", os.ErrNotExist)
+}
+
+func testGroupPanic() error {
+	panic("boom")
+}
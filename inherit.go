@@ -0,0 +1,213 @@
+package errstack
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// InheritPolicy is a policy to choose the stack call frames and the
+// pairs of labels and values which Errorf inherits when several
+// arguments have them.
+type InheritPolicy int32
+
+const (
+	// InheritLast inherits from the last argument which has them.
+	InheritLast InheritPolicy = iota
+
+	// InheritFirst inherits from the first argument which has them.
+	InheritFirst
+
+	// InheritAll inherits the stack call frames of every argument,
+	// which can be obtained with the Stacks function, and merges the
+	// pairs of labels and values of every argument in order.
+	// The Stack function returns the frames of the last argument as
+	// with InheritLast.
+	InheritAll
+
+	// InheritWrapped inherits from the last operand of the verb %w
+	// which has them. If no operand of %w has them, it inherits from
+	// the last argument as with InheritLast.
+	InheritWrapped
+)
+
+var inheritPolicy int32
+
+// SetInheritPolicy sets the policy used by Errorf, ErrorfCtx and
+// ErrorfSkip. The default policy is InheritLast.
+//
+// The policy can be overridden with the Inherit option of With.
+func SetInheritPolicy(policy InheritPolicy) {
+	atomic.StoreInt32(&inheritPolicy, int32(policy))
+}
+
+// Inherit returns an option to use policy instead of the policy set
+// with SetInheritPolicy.
+func Inherit(policy InheritPolicy) Option {
+	return func(o *options) {
+		o.inherit = policy
+		o.hasInherit = true
+	}
+}
+
+func (o options) inheritPolicy() InheritPolicy {
+	if o.hasInherit {
+		return o.inherit
+	}
+	return InheritPolicy(atomic.LoadInt32(&inheritPolicy))
+}
+
+// inherit returns the pairs of labels and values and the stack which
+// an error created with format and a inherits according to policy.
+// Under InheritAll, the stacks of all arguments are also returned if
+// there are more than one.
+func inherit(policy InheritPolicy, format string, a []interface{}) (fields []Field, s *stack, stacks []*stack) {
+	var wrapped []bool
+	if policy == InheritWrapped {
+		wrapped = wrappedOperands(format, len(a))
+	}
+	var errs, wrappedErrs []error
+	for i, v := range a {
+		if e2, ok := v.(error); ok {
+			errs = append(errs, e2)
+			if wrapped != nil && wrapped[i] {
+				wrappedErrs = append(wrappedErrs, e2)
+			}
+		}
+	}
+
+	switch policy {
+	case InheritAll:
+		for _, e2 := range errs {
+			for _, f := range inheritedFields(e2) {
+				if !containsField(fields, f) {
+					fields = append(fields, f)
+				}
+			}
+			if s2 := findStack(e2); s2 != nil && !containsStack(stacks, s2) {
+				stacks = append(stacks, s2)
+			}
+		}
+		if len(stacks) > 0 {
+			s = stacks[len(stacks)-1]
+		}
+		if len(stacks) < 2 {
+			stacks = nil
+		}
+		return fields, s, stacks
+	case InheritFirst:
+		for _, e2 := range errs {
+			if fields == nil {
+				fields = inheritedFields(e2)
+			}
+			if s == nil {
+				s = findStack(e2)
+			}
+		}
+		return fields, s, nil
+	case InheritWrapped:
+		fields, s = lastInherited(wrappedErrs)
+		if fields == nil || s == nil {
+			fields2, s2 := lastInherited(errs)
+			if fields == nil {
+				fields = fields2
+			}
+			if s == nil {
+				s = s2
+			}
+		}
+		return fields, s, nil
+	default:
+		fields, s = lastInherited(errs)
+		return fields, s, nil
+	}
+}
+
+func lastInherited(errs []error) (fields []Field, s *stack) {
+	for i := len(errs) - 1; i >= 0 && (fields == nil || s == nil); i-- {
+		if fields == nil {
+			fields = inheritedFields(errs[i])
+		}
+		if s == nil {
+			s = findStack(errs[i])
+		}
+	}
+	return fields, s
+}
+
+func containsStack(stacks []*stack, s *stack) bool {
+	for _, s2 := range stacks {
+		if s2 == s {
+			return true
+		}
+	}
+	return false
+}
+
+// wrappedOperands reports for each of n arguments whether it is an
+// operand of the verb %w in format. Flags, widths and precisions
+// including '*', and explicit argument indexes are taken into account
+// in the same way as the fmt package.
+func wrappedOperands(format string, n int) []bool {
+	wrapped := make([]bool, n)
+	argNum := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+		i, argNum = parseArgIndex(format, i, argNum)
+		i, argNum = skipWidth(format, i, argNum)
+		if i < len(format) && format[i] == '.' {
+			i++
+			i, argNum = parseArgIndex(format, i, argNum)
+			i, argNum = skipWidth(format, i, argNum)
+		}
+		i, argNum = parseArgIndex(format, i, argNum)
+		if i >= len(format) {
+			break
+		}
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		i += size - 1
+		if verb == '%' {
+			continue
+		}
+		if verb == 'w' && argNum < n {
+			wrapped[argNum] = true
+		}
+		argNum++
+	}
+	return wrapped
+}
+
+// parseArgIndex parses an explicit argument index like "[2]" at i,
+// and returns the index after it and the argument number to be used next.
+func parseArgIndex(format string, i, argNum int) (int, int) {
+	if i >= len(format) || format[i] != '[' {
+		return i, argNum
+	}
+	j := strings.IndexByte(format[i:], ']')
+	if j < 0 {
+		return i, argNum
+	}
+	k, err := strconv.Atoi(format[i+1 : i+j])
+	if err != nil || k < 1 {
+		return i + j + 1, argNum
+	}
+	return i + j + 1, k - 1
+}
+
+// skipWidth skips a width or a precision at i. A '*' consumes an argument.
+func skipWidth(format string, i, argNum int) (int, int) {
+	if i < len(format) && format[i] == '*' {
+		return i + 1, argNum + 1
+	}
+	for i < len(format) && '0' <= format[i] && format[i] <= '9' {
+		i++
+	}
+	return i, argNum
+}
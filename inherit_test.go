@@ -0,0 +1,74 @@
+package errstack_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestInheritPolicy(t *testing.T) {
+	errA := testInheritErrorA()
+	errB := testInheritErrorB()
+	nameA := "github.com/hnakamur/errstack_test.testInheritErrorA"
+	nameB := "github.com/hnakamur/errstack_test.testInheritErrorB"
+
+	testCases := []struct {
+		policy errstack.InheritPolicy
+		format string
+		args   []interface{}
+		stack  string
+		lv     []string
+	}{
+		{policy: errstack.InheritLast, format: "a=%v b=%v", args: []interface{}{errA, errB}, stack: nameB, lv: []string{"b", "2"}},
+		{policy: errstack.InheritFirst, format: "a=%v b=%v", args: []interface{}{errA, errB}, stack: nameA, lv: []string{"a", "1"}},
+		{policy: errstack.InheritAll, format: "a=%v b=%v", args: []interface{}{errA, errB}, stack: nameB, lv: []string{"a", "1", "b", "2"}},
+		{policy: errstack.InheritWrapped, format: "a=%w b=%v", args: []interface{}{errA, errB}, stack: nameA, lv: []string{"a", "1"}},
+		{policy: errstack.InheritWrapped, format: "a=%v b=%v", args: []interface{}{errA, errB}, stack: nameB, lv: []string{"b", "2"}},
+		{policy: errstack.InheritWrapped, format: "%[2]v %[1]w", args: []interface{}{errA, errB}, stack: nameA, lv: []string{"a", "1"}},
+		{policy: errstack.InheritWrapped, format: "%*d%% %v %w", args: []interface{}{3, 1, errA, errB}, stack: nameB, lv: []string{"b", "2"}},
+	}
+	for _, tc := range testCases {
+		errstack.SetInheritPolicy(tc.policy)
+		err := errstack.Errorf(tc.format, tc.args...)
+		errstack.SetInheritPolicy(errstack.InheritLast)
+
+		if got, want := errstack.Stack(err)[0].Name, tc.stack; got != want {
+			t.Errorf("unmatch stack for policy %d and %q, got:%s, want:%s", tc.policy, tc.format, got, want)
+		}
+		if got, want := errstack.LV(err), tc.lv; !reflect.DeepEqual(got, want) {
+			t.Errorf("lv unmatch for policy %d and %q, got:%v, want:%v", tc.policy, tc.format, got, want)
+		}
+
+		err2 := errstack.With(errstack.Inherit(tc.policy)).Errorf(tc.format, tc.args...)
+		if got, want := errstack.Stack(err2)[0].Name, tc.stack; got != want {
+			t.Errorf("unmatch stack with option for policy %d and %q, got:%s, want:%s", tc.policy, tc.format, got, want)
+		}
+	}
+}
+
+func TestInheritAllStacks(t *testing.T) {
+	errA := testInheritErrorA()
+	errB := testInheritErrorB()
+	err := errstack.With(errstack.Inherit(errstack.InheritAll)).Errorf("a=%v b=%v", errA, errB)
+
+	e, ok := err.(interface{ Unwrap() error }).Unwrap().(interface{ Stacks() [][]errstack.Frame })
+	if !ok {
+		t.Fatalf("error must have Stacks method")
+	}
+	for _, ss := range [][][]errstack.Frame{e.Stacks(), errstack.Stacks(err)} {
+		if got, want := len(ss), 2; got != want {
+			t.Fatalf("unmatch stack count, got:%d, want:%d", got, want)
+		}
+		testStackFrameNames(t, ss[0], []string{"github.com/hnakamur/errstack_test.testInheritErrorA"})
+		testStackFrameNames(t, ss[1], []string{"github.com/hnakamur/errstack_test.testInheritErrorB"})
+	}
+}
+
+func testInheritErrorA() error {
+	return errstack.WithLV(errstack.New("a"), "a", "1")
+}
+
+func testInheritErrorB() error {
+	return errstack.WithLV(errstack.New("b"), "b", "2")
+}
@@ -0,0 +1,74 @@
+package errstack
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+type joinError struct {
+	errs []error
+}
+
+// Join returns an error that wraps the given errors in the same way
+// as errors.Join, and call stack frames are generated and set to
+// the returned error.
+//
+// Any nil error values are discarded.
+// Join returns nil if every value in errs is nil.
+// The error message is the concatenation of the messages of the
+// non-nil errors, with a newline between each.
+//
+// The stack call frames and the pairs of labels and values of
+// each error are kept and can be obtained with Stacks and AllLV.
+// The verb %+v prints the stack call frames of the returned error
+// followed by the verbose output of each error.
+func Join(errs ...error) error {
+	n := 0
+	for _, err := range errs {
+		if err != nil {
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	e := &joinError{errs: make([]error, 0, n)}
+	for _, err := range errs {
+		if err != nil {
+			e.errs = append(e.errs, err)
+		}
+	}
+	return &errorWithStack{
+		err:   e,
+		stack: callers(3),
+	}
+}
+
+func (e *joinError) Error() string {
+	var b strings.Builder
+	for i, err := range e.errs {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+func (e *joinError) Unwrap() []error {
+	return e.errs
+}
+
+// writeJoinVerbose writes the verbose output of each error of e,
+// indented and headed by the index of the error.
+func writeJoinVerbose(b *bytes.Buffer, e *joinError, source int) {
+	for i, err := range e.errs {
+		b.WriteString("\n[")
+		b.WriteString(strconv.Itoa(i))
+		b.WriteString("] ")
+		var b2 bytes.Buffer
+		writeVerbose(&b2, err, source)
+		b.WriteString(strings.Replace(b2.String(), "\n", "\n    ", -1))
+	}
+}
@@ -0,0 +1,110 @@
+package errstack_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestJoin(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		if err := errstack.Join(); err != nil {
+			t.Errorf("unmatch result, got:%v, want:nil", err)
+		}
+		if err := errstack.Join(nil, nil); err != nil {
+			t.Errorf("unmatch result, got:%v, want:nil", err)
+		}
+	})
+	t.Run("message", func(t *testing.T) {
+		err := errstack.Join(errors.New("error1"), nil, errors.New("error2"))
+		if got, want := err.Error(), "error1\nerror2"; got != want {
+			t.Errorf("unmatch message, got:%q, want:%q", got, want)
+		}
+	})
+	t.Run("is", func(t *testing.T) {
+		err := errstack.Join(errors.New("error1"), os.ErrNotExist)
+		if got, want := errors.Is(err, os.ErrNotExist), true; got != want {
+			t.Errorf("unmatch Is result, got:%v, want:%v", got, want)
+		}
+	})
+	t.Run("stack", func(t *testing.T) {
+		err := testJoinLevel2()
+		testStackFrameNames(t, errstack.Stack(err), []string{
+			"github.com/hnakamur/errstack_test.testJoinLevel2",
+			"github.com/hnakamur/errstack_test.TestJoin.func4",
+		})
+		ss := errstack.Stacks(err)
+		if got, want := len(ss), 3; got != want {
+			t.Fatalf("unmatch stack count, got:%d, want:%d", got, want)
+		}
+		testStackFrameNames(t, ss[1], []string{"github.com/hnakamur/errstack_test.testJoinLevel1a"})
+		testStackFrameNames(t, ss[2], []string{"github.com/hnakamur/errstack_test.testJoinLevel1b"})
+	})
+	t.Run("lv", func(t *testing.T) {
+		err := testJoinLevel2()
+		if got, want := errstack.LV(err), []string{"item", "a"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+		}
+		want := [][]string{{"item", "a"}, {"item", "b"}}
+		if got := errstack.AllLV(err); !reflect.DeepEqual(got, want) {
+			t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+		}
+	})
+	t.Run("plusV", func(t *testing.T) {
+		got := fmt.Sprintf("%+v", testJoinLevel2())
+		lines := strings.Split(got, "\n")
+		want := []string{
+			"2 errors occurred:",
+			"github.com/hnakamur/errstack_test.testJoinLevel2",
+		}
+		for i, w := range want {
+			if lines[i] != w {
+				t.Errorf("unmatch line %d, got:%q, want:%q", i, lines[i], w)
+			}
+		}
+		for _, w := range []string{
+			"\n[0] error a\n    item=a\n    github.com/hnakamur/errstack_test.testJoinLevel1a\n    \t",
+			"\n[1] error b\n    item=b\n    github.com/hnakamur/errstack_test.testJoinLevel1b\n    \t",
+		} {
+			if !strings.Contains(got, w) {
+				t.Errorf("missing branch output, got:%q, want:%q", got, w)
+			}
+		}
+	})
+}
+
+func testJoinLevel2() error {
+	return errstack.Join(testJoinLevel1a(), nil, testJoinLevel1b())
+}
+func testJoinLevel1a() error {
+	return errstack.WithLV(errstack.New("error a"), "item", "a")
+}
+func testJoinLevel1b() error {
+	return errstack.WithLV(errstack.New("error b"), "item", "b")
+}
+
+func TestJoinWithLV(t *testing.T) {
+	err := errstack.WithLV(errstack.Join(
+		errstack.WithLV(errors.New("error1"), "item", "1"),
+		errstack.WithLV(errors.New("error2"), "item", "2"),
+	), "batch", "b1")
+
+	got := fmt.Sprintf("%+v", err)
+	want := "2 errors occurred:\nbatch=b1\n"
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("unmatch result,\n got:%s,\nwant prefix:%s", got, want)
+	}
+	if got, want := strings.Count(got, "item="), 2; got != want {
+		t.Errorf("unmatch item count, got:%d, want:%d", got, want)
+	}
+
+	err2 := errstack.Errorf("outer: %w", err)
+	if got, want := errstack.LV(err2), []string{"batch", "b1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+	}
+}
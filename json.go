@@ -0,0 +1,150 @@
+package errstack
+
+import (
+	"encoding/json"
+)
+
+type jsonError struct {
+	Message  string       `json:"message"`
+	Sentinel string       `json:"sentinel,omitempty"`
+	LV       []Field      `json:"lv,omitempty"`
+	Stack    []Frame      `json:"stack,omitempty"`
+	Cause    *jsonError   `json:"cause,omitempty"`
+	Causes   []*jsonError `json:"causes,omitempty"`
+}
+
+type jsonFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Inlined  bool   `json:"inlined,omitempty"`
+}
+
+// MarshalJSON returns the JSON encoding of err.
+// It returns null for a nil error.
+//
+// The encoding is an object with the following members:
+//
+//	message   the error message.
+//	sentinel  the name of the sentinel error registered with
+//	          RegisterSentinel if the error is one of them.
+//	lv        the labels and values as an array of Field objects,
+//	          in order.
+//	stack     the stack call frames as an array of Frame objects.
+//	cause     the error returned by the Unwrap() error method.
+//	causes    the errors returned by the Unwrap() []error method.
+//
+// The members except message are omitted if they are empty.
+//
+// An error whose message is the same as the error it wraps, such as
+// an error returned by Wrap or WithLV, is merged into that error.
+// The pairs of labels and values and the stack call frames are put
+// only on the outermost error which has them. For example, the stack
+// which Errorf inherited from its argument is not repeated in the cause.
+//
+// The encoding can be decoded with Decode.
+func MarshalJSON(err error) ([]byte, error) {
+	if err == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(Encode(err))
+}
+
+// MarshalJSON implements json.Marshaler.
+// It returns the same encoding as the MarshalJSON function.
+func (e *errorWithStack) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(e)
+}
+
+// MarshalJSON implements json.Marshaler.
+// It returns the same encoding as the MarshalJSON function.
+func (e *errorWithLV) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(e)
+}
+
+// MarshalJSON implements json.Marshaler.
+// It returns the same encoding as the MarshalJSON function.
+func (e *joinError) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(e)
+}
+
+// MarshalJSON implements json.Marshaler.
+// It returns the same encoding as the MarshalJSON function.
+func (e *redactedError) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(e)
+}
+
+// MarshalJSON implements json.Marshaler.
+// The encoding is the same as the MarshalJSON function.
+func (e *RemoteError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONError(e))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (e *RemoteError) UnmarshalJSON(data []byte) error {
+	var e2 jsonError
+	if err := json.Unmarshal(data, &e2); err != nil {
+		return err
+	}
+	*e = *newRemoteError(&e2)
+	return nil
+}
+
+func newJSONError(e *RemoteError) *jsonError {
+	e2 := &jsonError{
+		Message:  e.Msg,
+		Sentinel: e.Sentinel,
+		LV:       e.Pairs,
+		Stack:    e.Frames,
+	}
+	if e.Cause != nil {
+		e2.Cause = newJSONError(e.Cause)
+	}
+	for _, c := range e.Causes {
+		e2.Causes = append(e2.Causes, newJSONError(c))
+	}
+	return e2
+}
+
+func newRemoteError(e *jsonError) *RemoteError {
+	e2 := &RemoteError{
+		Msg:      e.Message,
+		Sentinel: e.Sentinel,
+		Pairs:    e.LV,
+		Frames:   e.Stack,
+	}
+	if e.Cause != nil {
+		e2.Cause = newRemoteError(e.Cause)
+	}
+	for _, c := range e.Causes {
+		e2.Causes = append(e2.Causes, newRemoteError(c))
+	}
+	return e2
+}
+
+// MarshalJSON implements json.Marshaler.
+// A frame is encoded as an object with "function", "file" and "line"
+// members, and the "inlined" member which is omitted if it is false.
+// PC and Entry are not encoded since they are meaningful only in the
+// process where the frame was obtained.
+func (f Frame) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonFrame{
+		Function: f.Name,
+		File:     f.Path,
+		Line:     f.Line,
+		Inlined:  f.Inlined,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *Frame) UnmarshalJSON(data []byte) error {
+	var f2 jsonFrame
+	if err := json.Unmarshal(data, &f2); err != nil {
+		return err
+	}
+	f.Name = f2.Function
+	f.Path = f2.File
+	f.Line = f2.Line
+	f.Inlined = f2.Inlined
+	return nil
+}
@@ -0,0 +1,160 @@
+package errstack_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		data, err := errstack.MarshalJSON(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := string(data), "null"; got != want {
+			t.Errorf("unmatch result, got:%s, want:%s", got, want)
+		}
+	})
+	t.Run("plain", func(t *testing.T) {
+		data, err := errstack.MarshalJSON(errors.New("my error"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := string(data), `{"message":"my error"}`; got != want {
+			t.Errorf("unmatch result, got:%s, want:%s", got, want)
+		}
+	})
+	t.Run("chain", func(t *testing.T) {
+		data, err := errstack.MarshalJSON(testJSONLevel3())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got testJSONError
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := got.Message, "top: middle: my error"; got != want {
+			t.Errorf("unmatch message, got:%s, want:%s", got, want)
+		}
+		if got.Stack != nil {
+			t.Errorf("unmatch stack, got:%v, want:nil", got.Stack)
+		}
+		middle := got.Cause
+		if middle == nil {
+			t.Fatalf("missing cause, got:%s", data)
+		}
+		if got, want := middle.Message, "middle: my error"; got != want {
+			t.Errorf("unmatch message, got:%s, want:%s", got, want)
+		}
+		want := []map[string]string{{"label": "reqID", "kind": "string", "value": "req1"}, {"label": "userID", "kind": "string", "value": "1"}}
+		if !reflect.DeepEqual(middle.LV, want) {
+			t.Errorf("unmatch lv, got:%v, want:%v", middle.LV, want)
+		}
+		if len(middle.Stack) == 0 {
+			t.Fatalf("missing stack, got:%s", data)
+		}
+		if got, want := middle.Stack[0].Name, "github.com/hnakamur/errstack_test.testJSONLevel1"; got != want {
+			t.Errorf("unmatch stack[0], got:%s, want:%s", got, want)
+		}
+		bottom := middle.Cause
+		if bottom == nil {
+			t.Fatalf("missing cause, got:%s", data)
+		}
+		if got, want := bottom.Message, "my error"; got != want {
+			t.Errorf("unmatch message, got:%s, want:%s", got, want)
+		}
+		if got, want := bottom.LV, want[:1]; !reflect.DeepEqual(got, want) {
+			t.Errorf("unmatch lv, got:%v, want:%v", got, want)
+		}
+		if bottom.Stack != nil || bottom.Cause != nil {
+			t.Errorf("unmatch bottom, got:%+v", bottom)
+		}
+	})
+	t.Run("join", func(t *testing.T) {
+		data, err := errstack.MarshalJSON(errstack.Join(errors.New("error1"), errors.New("error2")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got testJSONError
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(got.Causes), 2; got != want {
+			t.Fatalf("unmatch causes count, got:%d, want:%d", got, want)
+		}
+		if got, want := got.Causes[1].Message, "error2"; got != want {
+			t.Errorf("unmatch message, got:%s, want:%s", got, want)
+		}
+	})
+}
+
+func TestFrameJSON(t *testing.T) {
+	f := errstack.Frame{Name: "main.main", Path: "/src/main.go", Line: 12}
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `{"function":"main.main","file":"/src/main.go","line":12}`; got != want {
+		t.Errorf("unmatch result, got:%s, want:%s", got, want)
+	}
+	var got errstack.Frame
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != f {
+		t.Errorf("unmatch frame, got:%v, want:%v", got, f)
+	}
+}
+
+type testJSONError struct {
+	Message string              `json:"message"`
+	LV      []map[string]string `json:"lv"`
+	Stack   []errstack.Frame    `json:"stack"`
+	Cause   *testJSONError      `json:"cause"`
+	Causes  []*testJSONError    `json:"causes"`
+}
+
+func testJSONLevel3() error {
+	return fmt.Errorf("top: %w", testJSONLevel2())
+}
+func testJSONLevel2() error {
+	return errstack.WithLV(errstack.Errorf("middle: %w", testJSONLevel1()), "userID", "1")
+}
+func testJSONLevel1() error {
+	return errstack.WithLV(errstack.New("my error"), "reqID", "req1")
+}
+
+func TestJSONMarshalError(t *testing.T) {
+	testCases := []error{
+		errstack.New("my error"),
+		errstack.WithLV(errstack.New("my error"), "reqID", "req1"),
+		errstack.Join(errors.New("error1"), errors.New("error2")),
+		errstack.Redacted(errstack.WithLV(errstack.New("my error"), "reqID", "req1")),
+	}
+	for _, err := range testCases {
+		want, err2 := errstack.MarshalJSON(err)
+		if err2 != nil {
+			t.Fatal(err2)
+		}
+		got, err2 := json.Marshal(err)
+		if err2 != nil {
+			t.Fatal(err2)
+		}
+		if string(got) != string(want) {
+			t.Errorf("unmatch result for %T,\n got:%s,\nwant:%s", err, got, want)
+		}
+
+		got, err2 = json.Marshal(map[string]interface{}{"err": err})
+		if err2 != nil {
+			t.Fatal(err2)
+		}
+		if want := `{"err":` + string(want) + `}`; string(got) != want {
+			t.Errorf("unmatch result in map for %T,\n got:%s,\nwant:%s", err, got, want)
+		}
+	}
+}
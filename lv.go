@@ -1,18 +1,19 @@
 package errstack
 
 import (
-	"encoding/hex"
-	"errors"
 	"fmt"
-	"strconv"
 	"time"
 )
 
 // ErrorWithLV is an error with methods for adding a pair of label and value.
+//
+// The methods return a new error which has the pairs of labels and values
+// of the receiver and the added pair. The receiver is not modified.
 type ErrorWithLV interface {
 	error
 
 	String(label, value string) ErrorWithLV
+	Secret(label, value string) ErrorWithLV
 	Stringer(label string, value fmt.Stringer) ErrorWithLV
 	HexByte(label string, value byte) ErrorWithLV
 	HexBytes(label string, value []byte) ErrorWithLV
@@ -32,11 +33,17 @@ type ErrorWithLV interface {
 	Float64(label string, value float64) ErrorWithLV
 	Time(label string, value time.Time, format string) ErrorWithLV
 	UTCTime(label string, value time.Time) ErrorWithLV
+	Duration(label string, value time.Duration) ErrorWithLV
+	Group(label string, fields ...Field) ErrorWithLV
 }
 
 type errorWithLV struct {
-	err error
-	lv  []string
+	err    error
+	fields []Field
+
+	// inherited is the count of the leading fields which
+	// are inherited from the errors in err's chain.
+	inherited int
 }
 
 // WithLV wraps the error and attach pairs of labels and values
@@ -48,7 +55,9 @@ type errorWithLV struct {
 // first error which has LV() []string method and the lv argument.
 //
 // The pairs of labels and values can be get calling the LV
-// function to the wrapped error.
+// function to the wrapped error. The values added with the
+// methods of ErrorWithLV keep their types and can be get
+// calling the Fields function to the wrapped error.
 func WithLV(err error, lv ...string) ErrorWithLV {
 	if err == nil {
 		panic("err must not be nil")
@@ -57,29 +66,109 @@ func WithLV(err error, lv ...string) ErrorWithLV {
 		panic("lv must be label and value pairs")
 	}
 
-	e2 := &errorWithLV{err: err}
-	if lv2 := LV(err); len(lv2) > 0 {
-		e2.lv = make([]string, len(lv2)+len(lv))
-		copy(e2.lv, lv2)
-		copy(e2.lv[len(lv2):], lv)
-	} else {
-		e2.lv = lv
+	fields := inheritedFields(err)
+	e2 := &errorWithLV{
+		err:       err,
+		fields:    make([]Field, len(fields), len(fields)+len(lv)/2),
+		inherited: len(fields),
+	}
+	copy(e2.fields, fields)
+	for i := 0; i < len(lv); i += 2 {
+		e2.fields = append(e2.fields, stringField(lv[i], lv[i+1]))
 	}
 	return e2
 }
 
 // LV get the pairs of labels and values of the first error
-// which has LV() []string method in the err's chain.
+// which has LV() []string method in the err's tree.
+// The tree is traversed in the same order as Stack.
+//
+// Values which should be redacted are replaced with RedactedValue.
+// See LVUnredacted for getting the original values.
 func LV(err error) []string {
-	for {
-		if e2, ok := err.(interface{ LV() []string }); ok {
-			return e2.LV()
-		}
+	return fieldsLV(redactFields(findFields(err)))
+}
+
+// Fields get the fields of the first error which has
+// Fields() []Field or LV() []string method in the err's tree.
+// The tree is traversed in the same order as Stack.
+//
+// The pairs of labels and values of an error which has only
+// LV() []string method are returned as fields of KindString.
+//
+// Values which should be redacted are replaced with RedactedValue.
+// See FieldsUnredacted for getting the original values.
+func Fields(err error) []Field {
+	return redactFields(findFields(err))
+}
+
+func findFields(err error) []Field {
+	var fields []Field
+	walk(err, func(err error) bool {
+		fields = nodeFields(err)
+		return fields == nil
+	})
+	return fields
+}
 
-		if err = errors.Unwrap(err); err == nil {
+// inheritedFields returns the fields of the first error in err's chain
+// that has them, which WithLV and Errorf inherit.
+//
+// Unlike findFields, it does not descend into the errors joined with
+// Join or any other multi-errors, so that the pairs of one of the
+// joined errors are not attributed to the error wrapping them.
+func inheritedFields(err error) []Field {
+	for err != nil {
+		if fields := nodeFields(err); fields != nil {
+			return fields
+		}
+		e2, ok := err.(interface{ Unwrap() error })
+		if !ok {
 			return nil
 		}
+		err = e2.Unwrap()
 	}
+	return nil
+}
+
+// nodeFields returns the fields of err itself.
+// It does not traverse err's tree.
+func nodeFields(err error) []Field {
+	switch e2 := err.(type) {
+	case interface{ Fields() []Field }:
+		return e2.Fields()
+	case interface{ LV() []string }:
+		return stringFields(e2.LV())
+	}
+	return nil
+}
+
+// AllLV returns the pairs of labels and values of every error
+// which has LV() []string method in the err's tree, in the same
+// order as LV traverses the tree.
+//
+// Pairs shared by errors in the tree, for example pairs which
+// Errorf inherited from its argument, are returned only once.
+func AllLV(err error) [][]string {
+	var seen [][]Field
+	var lvs [][]string
+	walk(err, func(err error) bool {
+		if fields := nodeFields(err); len(fields) > 0 && !containsFields(seen, fields) {
+			seen = append(seen, fields)
+			lvs = append(lvs, fieldsLV(redactFields(fields)))
+		}
+		return true
+	})
+	return lvs
+}
+
+func containsFields(ss [][]Field, fields []Field) bool {
+	for _, s := range ss {
+		if &s[0] == &fields[0] && len(s) == len(fields) {
+			return true
+		}
+	}
+	return false
 }
 
 func (e *errorWithLV) Error() string {
@@ -91,108 +180,117 @@ func (e *errorWithLV) Unwrap() error {
 }
 
 func (e *errorWithLV) LV() []string {
-	return e.lv
+	return fieldsLV(redactFields(e.fields))
+}
+
+func (e *errorWithLV) Fields() []Field {
+	return e.fields
+}
+
+// with returns a new error which has the fields of e and f.
+// e is not modified, so that it is safe to add fields to an error
+// shared across goroutines, or to add different fields to an error
+// multiple times.
+func (e *errorWithLV) with(f Field) ErrorWithLV {
+	fields := make([]Field, len(e.fields)+1)
+	copy(fields, e.fields)
+	fields[len(e.fields)] = f
+	return &errorWithLV{err: e.err, fields: fields, inherited: e.inherited}
 }
 
 func (e *errorWithLV) String(label, value string) ErrorWithLV {
-	e.lv = append(e.lv, label, value)
-	return e
+	return e.with(stringField(label, value))
 }
 
 func (e *errorWithLV) Stringer(label string, value fmt.Stringer) ErrorWithLV {
-	e.lv = append(e.lv, label, value.String())
-	return e
+	return e.with(stringField(label, value.String()))
 }
 
 func (e *errorWithLV) HexByte(label string, value byte) ErrorWithLV {
-	e.lv = append(e.lv, label, hex.EncodeToString([]byte{value}))
-	return e
+	return e.with(bytesField(label, []byte{value}))
 }
 
 func (e *errorWithLV) HexBytes(label string, value []byte) ErrorWithLV {
-	e.lv = append(e.lv, label, hex.EncodeToString(value))
-	return e
+	return e.with(bytesField(label, value))
+}
+
+func (e *errorWithLV) Secret(label, value string) ErrorWithLV {
+	f := stringField(label, value)
+	f.secret = true
+	return e.with(f)
 }
 
 func (e *errorWithLV) Fmt(label string, format string, a ...interface{}) ErrorWithLV {
-	e.lv = append(e.lv, label, fmt.Sprintf(format, a...))
-	return e
+	return e.with(stringField(label, fmt.Sprintf(format, a...)))
 }
 
 func (e *errorWithLV) Bool(label string, value bool) ErrorWithLV {
-	e.lv = append(e.lv, label, strconv.FormatBool(value))
-	return e
+	return e.with(boolField(label, value))
 }
 
 func (e *errorWithLV) Int(label string, value int) ErrorWithLV {
-	e.lv = append(e.lv, label, strconv.Itoa(value))
-	return e
+	return e.with(int64Field(label, int64(value)))
 }
 
 func (e *errorWithLV) Int8(label string, value int8) ErrorWithLV {
-	e.lv = append(e.lv, label, strconv.Itoa(int(value)))
-	return e
+	return e.with(int64Field(label, int64(value)))
 }
 
 func (e *errorWithLV) Int16(label string, value int16) ErrorWithLV {
-	e.lv = append(e.lv, label, strconv.Itoa(int(value)))
-	return e
+	return e.with(int64Field(label, int64(value)))
 }
 
 func (e *errorWithLV) Int32(label string, value int32) ErrorWithLV {
-	e.lv = append(e.lv, label, strconv.FormatInt(int64(value), 10))
-	return e
+	return e.with(int64Field(label, int64(value)))
 }
 
 func (e *errorWithLV) Int64(label string, value int64) ErrorWithLV {
-	e.lv = append(e.lv, label, strconv.FormatInt(int64(value), 10))
-	return e
+	return e.with(int64Field(label, value))
 }
 
 func (e *errorWithLV) Uint(label string, value uint) ErrorWithLV {
-	e.lv = append(e.lv, label, strconv.FormatUint(uint64(value), 10))
-	return e
+	return e.with(uint64Field(label, uint64(value)))
 }
 
 func (e *errorWithLV) Uint8(label string, value uint8) ErrorWithLV {
-	e.lv = append(e.lv, label, strconv.FormatUint(uint64(value), 10))
-	return e
+	return e.with(uint64Field(label, uint64(value)))
 }
 
 func (e *errorWithLV) Uint16(label string, value uint16) ErrorWithLV {
-	e.lv = append(e.lv, label, strconv.FormatUint(uint64(value), 10))
-	return e
+	return e.with(uint64Field(label, uint64(value)))
 }
 
 func (e *errorWithLV) Uint32(label string, value uint32) ErrorWithLV {
-	e.lv = append(e.lv, label, strconv.FormatUint(uint64(value), 10))
-	return e
+	return e.with(uint64Field(label, uint64(value)))
 }
 
 func (e *errorWithLV) Uint64(label string, value uint64) ErrorWithLV {
-	e.lv = append(e.lv, label, strconv.FormatUint(uint64(value), 10))
-	return e
+	return e.with(uint64Field(label, value))
 }
 
 func (e *errorWithLV) Float32(label string, value float32) ErrorWithLV {
-	e.lv = append(e.lv, label, strconv.FormatFloat(float64(value), 'g', -1, 32))
-	return e
+	return e.with(floatField(label, float64(value), 32))
 }
 
 func (e *errorWithLV) Float64(label string, value float64) ErrorWithLV {
-	e.lv = append(e.lv, label, strconv.FormatFloat(value, 'g', -1, 64))
-	return e
+	return e.with(floatField(label, value, 64))
 }
 
 func (e *errorWithLV) Time(label string, value time.Time, format string) ErrorWithLV {
 	if format == "" {
 		format = time.RFC3339
 	}
-	e.lv = append(e.lv, label, value.Format(format))
-	return e
+	return e.with(timeField(label, value, format))
 }
 
 func (e *errorWithLV) UTCTime(label string, value time.Time) ErrorWithLV {
-	e.lv = append(e.lv, label, value.Format("2006-01-02T15:04:05.999999Z"))
-	return e
+	return e.with(timeField(label, value, "2006-01-02T15:04:05.999999Z"))
+}
+
+func (e *errorWithLV) Duration(label string, value time.Duration) ErrorWithLV {
+	return e.with(durationField(label, value))
+}
+
+func (e *errorWithLV) Group(label string, fields ...Field) ErrorWithLV {
+	return e.with(groupField(label, fields))
 }
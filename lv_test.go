@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
@@ -243,3 +244,57 @@ func TestErrorWithLV(t *testing.T) {
 		}
 	})
 }
+
+func TestErrorWithLVImmutable(t *testing.T) {
+	t.Run("branches", func(t *testing.T) {
+		base := errstack.WithLV(errors.New("my error"), "reqID", "req1")
+		err1 := base.String("userID", "user1")
+		err2 := base.String("userID", "user2")
+		if got, want := errstack.LV(base), []string{"reqID", "req1"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+		}
+		if got, want := errstack.LV(err1), []string{"reqID", "req1", "userID", "user1"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+		}
+		if got, want := errstack.LV(err2), []string{"reqID", "req1", "userID", "user2"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+		}
+	})
+	t.Run("callerSlice", func(t *testing.T) {
+		lv := []string{"reqID", "req1", "userID", "user1"}
+		err := errstack.WithLV(errors.New("my error"), lv[:2]...)
+		lv[1] = "modified"
+		if got, want := errstack.LV(err), []string{"reqID", "req1"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+		}
+	})
+	t.Run("callerBytes", func(t *testing.T) {
+		b := []byte{'\xba', '\xbe'}
+		err := errstack.WithLV(errors.New("my error")).HexBytes("bytes", b)
+		b[0] = 0
+		if got, want := errstack.LV(err), []string{"bytes", "babe"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+		}
+	})
+	t.Run("concurrent", func(t *testing.T) {
+		// Run with -race to detect data races on the shared error.
+		base := errstack.WithLV(errors.New("my error"), "reqID", "req1")
+		const n = 8
+		errs := make([]error, n)
+		var wg sync.WaitGroup
+		wg.Add(n)
+		for i := 0; i < n; i++ {
+			go func(i int) {
+				defer wg.Done()
+				errs[i] = base.Int("worker", i).String("state", "failed")
+			}(i)
+		}
+		wg.Wait()
+		for i := 0; i < n; i++ {
+			want := []string{"reqID", "req1", "worker", fmt.Sprint(i), "state", "failed"}
+			if got := errstack.LV(errs[i]); !reflect.DeepEqual(got, want) {
+				t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package errstack
+
+import "errors"
+
+// Option is an option of creating errors with a Creator.
+type Option func(*options)
+
+// Skip returns an option to skip frames in addition to the frame of
+// the caller of the methods of a Creator, in the same way as NewSkip.
+func Skip(skip int) Option {
+	return func(o *options) {
+		o.skip = nonNegative(skip)
+	}
+}
+
+// Depth returns an option to record at most depth call stack frames
+// instead of MaxFrames.
+func Depth(depth int) Option {
+	return func(o *options) {
+		o.depth = nonNegative(depth)
+	}
+}
+
+// ForceCapture returns an option to always generate call stack frames
+// even if an argument of Errorf or the error passed to Wrap already has
+// them.
+func ForceCapture() Option {
+	return func(o *options) {
+		o.force = true
+	}
+}
+
+// Creator creates errors with options.
+type Creator struct {
+	opts options
+}
+
+// With returns a Creator with the options.
+//
+//	err := errstack.With(errstack.Skip(1), errstack.Depth(8)).Errorf("read config: %w", err)
+func With(opts ...Option) *Creator {
+	c := &Creator{}
+	for _, opt := range opts {
+		opt(&c.opts)
+	}
+	return c
+}
+
+// New is the same as the New function except that the options of c
+// are used.
+func (c *Creator) New(text string) error {
+	return &errorWithStack{
+		err:   errors.New(text),
+		stack: callersN(3+c.opts.skip, c.opts.depth),
+	}
+}
+
+// Errorf is the same as the Errorf function except that the options
+// of c are used.
+func (c *Creator) Errorf(format string, a ...interface{}) error {
+	opts := c.opts
+	opts.skip += 4
+	return errorf(opts, format, a...)
+}
+
+// Wrap is the same as the Wrap function except that the options
+// of c are used.
+func (c *Creator) Wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	var s *stack
+	if !c.opts.force {
+		s = findStack(err)
+	}
+	if s == nil {
+		s = callersN(3+c.opts.skip, c.opts.depth)
+	}
+	return &errorWithStack{
+		err:   err,
+		stack: s,
+		wrap:  wrapPointStack(3 + c.opts.skip),
+	}
+}
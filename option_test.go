@@ -0,0 +1,69 @@
+package errstack_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestWith(t *testing.T) {
+	t.Run("skip", func(t *testing.T) {
+		names := []string{"github.com/hnakamur/errstack_test.TestWith.func1"}
+		testStackFrameNames(t, errstack.Stack(testWithHelperNew()), names)
+		testStackFrameNames(t, errstack.Stack(testWithHelperErrorf()), names)
+		testStackFrameNames(t, errstack.Stack(testWithHelperWrap(os.ErrNotExist)), names)
+	})
+	t.Run("depth", func(t *testing.T) {
+		err := errstack.With(errstack.Depth(2)).Errorf("my error: %w", os.ErrNotExist)
+		frames := errstack.Stack(err)
+		if got, want := len(frames), 2; got != want {
+			t.Errorf("unmatch frame count, got:%d, want:%d", got, want)
+		}
+		testStackFrameNames(t, frames, []string{
+			"github.com/hnakamur/errstack_test.TestWith.func2",
+			"testing.tRunner",
+		})
+	})
+	t.Run("forceCapture", func(t *testing.T) {
+		inner := testWithInner()
+		testCases := []struct {
+			err  error
+			name string
+		}{
+			{err: errstack.With().Errorf("outer: %w", inner), name: "github.com/hnakamur/errstack_test.testWithInner"},
+			{err: errstack.With(errstack.ForceCapture()).Errorf("outer: %w", inner), name: "github.com/hnakamur/errstack_test.TestWith.func3"},
+			{err: errstack.With(errstack.ForceCapture()).Wrap(inner), name: "github.com/hnakamur/errstack_test.TestWith.func3"},
+		}
+		for i, tc := range testCases {
+			if got, want := errstack.Stack(tc.err)[0].Name, tc.name; got != want {
+				t.Errorf("unmatch frames[0].Name of case %d, got:%s, want:%s", i, got, want)
+			}
+		}
+		if got, want := errstack.LV(testCases[1].err), []string{"reqID", "req1"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+		}
+	})
+	t.Run("nil", func(t *testing.T) {
+		if err := errstack.With().Wrap(nil); err != nil {
+			t.Errorf("unmatch result, got:%v, want:nil", err)
+		}
+	})
+}
+
+func testWithHelperNew() error {
+	return errstack.With(errstack.Skip(1)).New("my error")
+}
+
+func testWithHelperErrorf() error {
+	return errstack.With(errstack.Skip(1)).Errorf("my error: %w", os.ErrNotExist)
+}
+
+func testWithHelperWrap(err error) error {
+	return errstack.With(errstack.Skip(1)).Wrap(err)
+}
+
+func testWithInner() error {
+	return errstack.WithLV(errstack.New("inner"), "reqID", "req1")
+}
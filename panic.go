@@ -0,0 +1,80 @@
+package errstack
+
+import (
+	"fmt"
+	"strings"
+)
+
+type panicError struct {
+	value interface{}
+}
+
+// Recover recovers from a panic and sets an error converted with
+// FromPanic to *errp. It must be called directly as a deferred
+// function like:
+//
+//	defer errstack.Recover(&err)
+//
+// If there is no panic, *errp is left unchanged.
+func Recover(errp *error) {
+	if v := recover(); v != nil {
+		*errp = FromPanic(v)
+	}
+}
+
+// FromPanic converts a value returned by recover to an error.
+// It returns nil if v is nil.
+//
+// When FromPanic is called during panicking, for example from a
+// deferred function, call stack frames starting at the frame which
+// panicked are set to the returned error. Otherwise call stack
+// frames starting at the caller of FromPanic are set.
+//
+// If v is an error, including runtime.Error, it can be obtained
+// with errors.Is and errors.As from the returned error.
+//
+// The panic value formatted with fmt.Sprint is attached to the
+// returned error as the value of the "panic" label.
+func FromPanic(v interface{}) error {
+	if v == nil {
+		return nil
+	}
+	err := &errorWithStack{
+		err:   &panicError{value: v},
+		stack: panicStack(4),
+	}
+	return WithLV(err, "panic", fmt.Sprint(v))
+}
+
+// panicStack returns call stack frames starting at the frame which
+// panicked. The frames of runtime.gopanic and its callees and the
+// frames of the runtime functions which raised the panic are dropped.
+func panicStack(skip int) *stack {
+	s := callers(skip)
+	frames := resolveFrames(s.pcs)
+	for i, f := range frames {
+		if f.Name != "runtime.gopanic" {
+			continue
+		}
+		j := i + 1
+		for j < len(frames) && strings.HasPrefix(frames[j].Name, "runtime.") {
+			j++
+		}
+		if j == len(frames) {
+			j = i + 1
+		}
+		return &stack{frames: filterFrames(frames[j:]), goroutine: s.goroutine}
+	}
+	return &stack{frames: filterFrames(frames), goroutine: s.goroutine}
+}
+
+func (e *panicError) Error() string {
+	return "panic: " + fmt.Sprint(e.value)
+}
+
+func (e *panicError) Unwrap() error {
+	if err, ok := e.value.(error); ok {
+		return err
+	}
+	return nil
+}
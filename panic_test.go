@@ -0,0 +1,86 @@
+package errstack_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestRecover(t *testing.T) {
+	t.Run("value", func(t *testing.T) {
+		err := testRecoverValueLevel2()
+		if got, want := err.Error(), "panic: boom"; got != want {
+			t.Errorf("unmatch message, got:%q, want:%q", got, want)
+		}
+		if got, want := errstack.LV(err), []string{"panic", "boom"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+		}
+		testStackFrameNames(t, errstack.Stack(err), []string{
+			"github.com/hnakamur/errstack_test.testRecoverValueLevel1",
+			"github.com/hnakamur/errstack_test.testRecoverValueLevel2",
+		})
+	})
+	t.Run("runtimeError", func(t *testing.T) {
+		err := testRecoverRuntimeErrorLevel2()
+		var re runtime.Error
+		if !errors.As(err, &re) {
+			t.Fatalf("unmatch As result, got:%v, want:%v", false, true)
+		}
+		testStackFrameNames(t, errstack.Stack(err), []string{
+			"github.com/hnakamur/errstack_test.testRecoverRuntimeErrorLevel1",
+			"github.com/hnakamur/errstack_test.testRecoverRuntimeErrorLevel2",
+		})
+	})
+	t.Run("error", func(t *testing.T) {
+		err := testRecoverErrorLevel2()
+		if got, want := errors.Is(err, os.ErrNotExist), true; got != want {
+			t.Errorf("unmatch Is result, got:%v, want:%v", got, want)
+		}
+	})
+	t.Run("noPanic", func(t *testing.T) {
+		err := testRecoverNoPanic()
+		if err != nil {
+			t.Errorf("unmatch result, got:%v, want:nil", err)
+		}
+	})
+}
+
+func TestFromPanic(t *testing.T) {
+	if err := errstack.FromPanic(nil); err != nil {
+		t.Errorf("unmatch result, got:%v, want:nil", err)
+	}
+	err := errstack.FromPanic("boom")
+	testStackFrameNames(t, errstack.Stack(err), []string{
+		"github.com/hnakamur/errstack_test.TestFromPanic",
+	})
+}
+
+func testRecoverValueLevel2() (err error) {
+	defer errstack.Recover(&err)
+	testRecoverValueLevel1()
+	return nil
+}
+func testRecoverValueLevel1() { panic("boom") }
+
+func testRecoverRuntimeErrorLevel2() (err error) {
+	defer errstack.Recover(&err)
+	testRecoverRuntimeErrorLevel1(nil)
+	return nil
+}
+func testRecoverRuntimeErrorLevel1(m map[string]int) { m["a"] = 1 }
+
+func testRecoverErrorLevel2() (err error) {
+	defer func() {
+		err = errstack.FromPanic(recover())
+	}()
+	panic(os.ErrNotExist)
+}
+
+func testRecoverNoPanic() (err error) {
+	defer errstack.Recover(&err)
+	return nil
+}
@@ -0,0 +1,264 @@
+package errstack
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// ColorMode is a mode of using ANSI colors in Print.
+type ColorMode int
+
+const (
+	// ColorAuto uses colors if the writer is a terminal and neither
+	// the NO_COLOR environment variable is set nor TERM is "dumb".
+	ColorAuto ColorMode = iota
+
+	// ColorAlways always uses colors.
+	ColorAlways
+
+	// ColorNever never uses colors.
+	ColorNever
+)
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiDim   = "\x1b[2m"
+	ansiRed   = "\x1b[31m"
+	ansiCyan  = "\x1b[36m"
+)
+
+// PrintOption is an option of Print.
+type PrintOption func(*printer)
+
+// WithColor returns an option to set the mode of using colors.
+// The default mode is ColorAuto.
+func WithColor(mode ColorMode) PrintOption {
+	return func(p *printer) {
+		p.mode = mode
+	}
+}
+
+// WithModules returns an option to set the module paths whose frames are
+// highlighted. By default, the path of the main module of the binary
+// obtained with runtime/debug.ReadBuildInfo is used.
+func WithModules(paths ...string) PrintOption {
+	return func(p *printer) {
+		p.modules = append([]string(nil), paths...)
+	}
+}
+
+type printer struct {
+	mode    ColorMode
+	modules []string
+	color   bool
+}
+
+// Print writes err in a human-oriented layout to w.
+//
+// The error message is followed by the pairs of labels and values
+// shown as a table, and the call stack frames. Frames of the modules set
+// with WithModules and of the main package are highlighted, and frames
+// of the standard library are dimmed. Consecutive repetitions of the same
+// frame or the same sequence of frames, for example by recursion, are
+// collapsed into a line like "... 37 more identical frames".
+// The errors joined with Join are printed after the frames of err
+// in the same way as the verb %+v.
+//
+// Frames are filtered with the filter set with SetFrameFilter, and values
+// which should be redacted are replaced with RedactedValue.
+func Print(w io.Writer, err error, opts ...PrintOption) error {
+	p := &printer{}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.modules == nil {
+		if info, ok := debug.ReadBuildInfo(); ok && info.Main.Path != "" {
+			p.modules = []string{info.Main.Path}
+		}
+	}
+	switch p.mode {
+	case ColorAlways:
+		p.color = true
+	case ColorAuto:
+		p.color = isTerminal(w) && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb"
+	}
+
+	var b bytes.Buffer
+	if err == nil {
+		b.WriteString("<nil>\n")
+	} else {
+		p.printError(&b, err, "")
+	}
+	_, err = w.Write(b.Bytes())
+	return err
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+func (p *printer) printError(b *bytes.Buffer, err error, indent string) {
+	j := findJoin(err)
+	msg := err.Error()
+	fields := Fields(err)
+	frames := Stack(err)
+	if j != nil {
+		msg = joinHeader(err, j)
+		fields = redactFields(inheritedFields(err))
+		frames = chainStack(err)
+	}
+
+	b.WriteString(indent)
+	p.writeColored(b, ansiBold+ansiRed, msg)
+	b.WriteByte('\n')
+	p.printLV(b, fields, indent)
+	p.printFrames(b, filterFrames(frames), indent)
+
+	if j == nil {
+		return
+	}
+	for i, err2 := range j.errs {
+		b.WriteString(indent)
+		b.WriteString("[")
+		b.WriteString(strconv.Itoa(i))
+		b.WriteString("]\n")
+		p.printError(b, err2, indent+"    ")
+	}
+}
+
+func (p *printer) printLV(b *bytes.Buffer, fields []Field, indent string) {
+	width := 0
+	for _, f := range fields {
+		if len(f.Label) > width {
+			width = len(f.Label)
+		}
+	}
+	for _, f := range fields {
+		b.WriteString(indent)
+		b.WriteString("  ")
+		p.writeColored(b, ansiCyan, f.Label)
+		b.WriteString(strings.Repeat(" ", width-len(f.Label)+2))
+		b.WriteString(f.String())
+		b.WriteByte('\n')
+	}
+}
+
+func (p *printer) printFrames(b *bytes.Buffer, frames []Frame, indent string) {
+	for i := 0; i < len(frames); {
+		period, reps := repetition(frames[i:])
+		for k := 0; k < period; k++ {
+			p.printFrame(b, &frames[i+k], indent)
+		}
+		i += period
+		if reps > 1 {
+			n := (reps - 1) * period
+			b.WriteString(indent)
+			b.WriteString("  ")
+			msg := "... " + strconv.Itoa(n) + " more identical frame"
+			if n > 1 {
+				msg += "s"
+			}
+			p.writeColored(b, ansiDim, msg)
+			b.WriteByte('\n')
+			i += n
+		}
+	}
+}
+
+func (p *printer) printFrame(b *bytes.Buffer, f *Frame, indent string) {
+	style := ""
+	switch {
+	case p.isOwnFrame(f):
+		style = ansiBold
+	case isStdFrame(f):
+		style = ansiDim
+	}
+	b.WriteString(indent)
+	b.WriteString("  ")
+	p.writeColored(b, style, f.Name)
+	b.WriteByte('\n')
+	b.WriteString(indent)
+	b.WriteString("      ")
+	p.writeColored(b, ansiDim, f.Path+":"+strconv.Itoa(f.Line))
+	b.WriteByte('\n')
+}
+
+func (p *printer) writeColored(b *bytes.Buffer, style, s string) {
+	if !p.color || style == "" {
+		b.WriteString(s)
+		return
+	}
+	b.WriteString(style)
+	b.WriteString(s)
+	b.WriteString(ansiReset)
+}
+
+func (p *printer) isOwnFrame(f *Frame) bool {
+	pkg := f.Package()
+	if pkg == "main" {
+		return true
+	}
+	for _, m := range p.modules {
+		if pkg == m || strings.HasPrefix(pkg, m+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isStdFrame reports whether f is a frame of the standard library,
+// whose import path has no dot in the first element.
+func isStdFrame(f *Frame) bool {
+	pkg := f.Package()
+	if pkg == "" || pkg == "main" {
+		return false
+	}
+	if i := strings.IndexByte(pkg, '/'); i >= 0 {
+		pkg = pkg[:i]
+	}
+	return !strings.Contains(pkg, ".")
+}
+
+// maxRepetitionPeriod is the maximum length of a sequence of frames
+// which repetition detects.
+const maxRepetitionPeriod = 8
+
+// repetition returns the length of the sequence of frames at the start
+// of frames which is repeated most, and the count of the consecutive
+// occurrences of the sequence. It returns 1 and 1 if no sequence is
+// repeated.
+func repetition(frames []Frame) (period, reps int) {
+	period, reps = 1, 1
+	for p := 1; p <= maxRepetitionPeriod && 2*p <= len(frames); p++ {
+		r := 1
+		for (r+1)*p <= len(frames) && sameFrames(frames[:p], frames[r*p:(r+1)*p]) {
+			r++
+		}
+		if r > 1 && (r-1)*p > (reps-1)*period {
+			period, reps = p, r
+		}
+	}
+	return period, reps
+}
+
+func sameFrames(a, b []Frame) bool {
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Path != b[i].Path || a[i].Line != b[i].Line {
+			return false
+		}
+	}
+	return true
+}
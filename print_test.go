@@ -0,0 +1,100 @@
+package errstack_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestPrint(t *testing.T) {
+	err := errstack.WithLV(testPrintStackError{}, "reqID", "req1", "user", "u1")
+
+	t.Run("noColor", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := errstack.Print(&b, err, errstack.WithColor(errstack.ColorNever), errstack.WithModules("example.com/app")); err != nil {
+			t.Fatal(err)
+		}
+		want := "my error\n" +
+			"  reqID  req1\n" +
+			"  user   u1\n" +
+			"  example.com/app.walk\n" +
+			"      /app/walk.go:10\n" +
+			"  example.com/app.walk\n" +
+			"      /app/walk.go:12\n" +
+			"  ... 3 more identical frames\n" +
+			"  example.com/app.even\n" +
+			"      /app/parity.go:5\n" +
+			"  example.com/app.odd\n" +
+			"      /app/parity.go:9\n" +
+			"  ... 2 more identical frames\n" +
+			"  fmt.Sprintf\n" +
+			"      $GOROOT/src/fmt/print.go:239\n"
+		if got := b.String(); got != want {
+			t.Errorf("unmatch result,\n got:%s,\nwant:%s", got, want)
+		}
+	})
+	t.Run("auto", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := errstack.Print(&b, err); err != nil {
+			t.Fatal(err)
+		}
+		if got := b.String(); strings.Contains(got, "\x1b[") {
+			t.Errorf("colors must not be used for a non-terminal, got:%q", got)
+		}
+	})
+	t.Run("color", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := errstack.Print(&b, err, errstack.WithColor(errstack.ColorAlways), errstack.WithModules("example.com/app")); err != nil {
+			t.Fatal(err)
+		}
+		got := b.String()
+		for _, want := range []string{
+			"\x1b[1mexample.com/app.walk\x1b[0m\n",
+			"\x1b[2mfmt.Sprintf\x1b[0m\n",
+			"\x1b[36mreqID\x1b[0m  req1\n",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("%q not found in result, got:%q", want, got)
+			}
+		}
+	})
+	t.Run("join", func(t *testing.T) {
+		var b bytes.Buffer
+		err := errstack.WithLV(errstack.Join(errstack.WithLV(errstack.New("err1"), "reqID", "req1"), testPrintStackError{}), "batch", "b1")
+		errstack.SetFrameFilter(errstack.ExcludePackages("github.com/hnakamur/errstack_test", "testing", "runtime", "fmt"))
+		defer errstack.SetFrameFilter(nil)
+		if err := errstack.Print(&b, err, errstack.WithColor(errstack.ColorNever)); err != nil {
+			t.Fatal(err)
+		}
+		want := "2 errors occurred:\n" +
+			"  batch  b1\n" +
+			"[0]\n" +
+			"    err1\n" +
+			"      reqID  req1\n" +
+			"[1]\n" +
+			"    my error\n" +
+			"      example.com/app.walk\n"
+		if got := b.String(); !strings.HasPrefix(got, want) {
+			t.Errorf("unmatch result,\n got:%s,\nwant prefix:%s", got, want)
+		}
+	})
+}
+
+type testPrintStackError struct{}
+
+func (testPrintStackError) Error() string { return "my error" }
+
+func (testPrintStackError) Stack() []errstack.Frame {
+	frames := []errstack.Frame{{Name: "example.com/app.walk", Path: "/app/walk.go", Line: 10}}
+	for i := 0; i < 4; i++ {
+		frames = append(frames, errstack.Frame{Name: "example.com/app.walk", Path: "/app/walk.go", Line: 12})
+	}
+	for i := 0; i < 2; i++ {
+		frames = append(frames,
+			errstack.Frame{Name: "example.com/app.even", Path: "/app/parity.go", Line: 5},
+			errstack.Frame{Name: "example.com/app.odd", Path: "/app/parity.go", Line: 9})
+	}
+	return append(frames, errstack.Frame{Name: "fmt.Sprintf", Path: "$GOROOT/src/fmt/print.go", Line: 239})
+}
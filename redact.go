@@ -0,0 +1,141 @@
+package errstack
+
+import (
+	"regexp"
+	"sync"
+)
+
+// RedactedValue is the value which replaces values which should be
+// redacted.
+const RedactedValue = "[REDACTED]"
+
+var redaction struct {
+	mu       sync.RWMutex
+	labels   []string
+	patterns []*regexp.Regexp
+}
+
+type redactedError struct {
+	err    error
+	fields []Field
+}
+
+// RedactLabels registers labels whose values should be redacted.
+//
+// Values of registered labels and values added with the Secret method
+// of ErrorWithLV are replaced with RedactedValue in the results of LV,
+// Fields, AllLV and CollectLV, in the JSON encoding, in the slog values
+// and in the output of the verb %+v.
+func RedactLabels(labels ...string) {
+	redaction.mu.Lock()
+	defer redaction.mu.Unlock()
+	redaction.labels = append(redaction.labels, labels...)
+}
+
+// RedactPattern registers a pattern of labels whose values should be
+// redacted. See RedactLabels for where values are redacted.
+func RedactPattern(pattern *regexp.Regexp) {
+	redaction.mu.Lock()
+	defer redaction.mu.Unlock()
+	redaction.patterns = append(redaction.patterns, pattern)
+}
+
+func shouldRedact(label string) bool {
+	redaction.mu.RLock()
+	defer redaction.mu.RUnlock()
+	for _, l := range redaction.labels {
+		if l == label {
+			return true
+		}
+	}
+	for _, p := range redaction.patterns {
+		if p.MatchString(label) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redacted returns an error which has the same message and tree as err,
+// and whose fields are the result of Fields(err).
+//
+// Since the fields of the returned error are already redacted, even
+// LVUnredacted and FieldsUnredacted return redacted values for it.
+// It returns nil if err is nil.
+func Redacted(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &redactedError{err: err, fields: Fields(err)}
+}
+
+// LVUnredacted is the same as LV except that values are not redacted.
+// It is intended for in-process use and the result should not be logged.
+func LVUnredacted(err error) []string {
+	return fieldsLV(findFields(err))
+}
+
+// FieldsUnredacted is the same as Fields except that values are not
+// redacted. It is intended for in-process use and the result should not
+// be logged.
+func FieldsUnredacted(err error) []Field {
+	return findFields(err)
+}
+
+// redact returns the field with its value replaced with RedactedValue
+// and true if the field should be redacted, or the field itself and
+// false otherwise.
+func (f Field) redact() (Field, bool) {
+	if f.secret || shouldRedact(f.Label) {
+		return stringField(f.Label, RedactedValue), true
+	}
+	if f.Kind == KindGroup {
+		fields, _ := f.any.([]Field)
+		if fields, ok := redactFieldsIfNeeded(fields); ok {
+			return Field{Label: f.Label, Kind: KindGroup, any: fields}, true
+		}
+	}
+	return f, false
+}
+
+// redactFields returns fields with values which should be redacted
+// replaced with RedactedValue. It returns fields itself if there is
+// no value to redact, so that the identity of fields is kept.
+func redactFields(fields []Field) []Field {
+	fields, _ = redactFieldsIfNeeded(fields)
+	return fields
+}
+
+func redactFieldsIfNeeded(fields []Field) ([]Field, bool) {
+	var fields2 []Field
+	for i, f := range fields {
+		f2, ok := f.redact()
+		if ok && fields2 == nil {
+			fields2 = make([]Field, len(fields))
+			copy(fields2, fields[:i])
+		}
+		if fields2 != nil {
+			fields2[i] = f2
+		}
+	}
+	if fields2 == nil {
+		return fields, false
+	}
+	return fields2, true
+}
+
+func (e *redactedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+func (e *redactedError) LV() []string {
+	return fieldsLV(e.fields)
+}
+
+func (e *redactedError) Fields() []Field {
+	return e.fields
+}
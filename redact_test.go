@@ -0,0 +1,86 @@
+package errstack_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func init() {
+	errstack.RedactLabels("testRedactToken")
+	errstack.RedactPattern(regexp.MustCompile(`^testRedactEmail`))
+}
+
+func TestRedact(t *testing.T) {
+	err := errstack.WithLV(errstack.New("my error"), "testRedactToken", "t0k3n").
+		Secret("password", "p4ss").
+		String("testRedactEmailWork", "foo@example.com").
+		String("reqID", "req1")
+	err2 := fmt.Errorf("outer: %w", err)
+
+	want := []string{
+		"testRedactToken", errstack.RedactedValue,
+		"password", errstack.RedactedValue,
+		"testRedactEmailWork", errstack.RedactedValue,
+		"reqID", "req1",
+	}
+	if got := errstack.LV(err2); !reflect.DeepEqual(got, want) {
+		t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+	}
+	if got := errstack.CollectLV(err2, errstack.LVKeepAll); !reflect.DeepEqual(got, want) {
+		t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+	}
+	if got, want := errstack.Fields(err2)[1].Value(), errstack.RedactedValue; got != want {
+		t.Errorf("unmatch value, got:%v, want:%v", got, want)
+	}
+
+	wantUnredacted := []string{
+		"testRedactToken", "t0k3n",
+		"password", "p4ss",
+		"testRedactEmailWork", "foo@example.com",
+		"reqID", "req1",
+	}
+	if got := errstack.LVUnredacted(err2); !reflect.DeepEqual(got, wantUnredacted) {
+		t.Errorf("lv unmatch, got:%v, want:%v", got, wantUnredacted)
+	}
+	if got, want := errstack.FieldsUnredacted(err2)[1].Value(), "p4ss"; got != want {
+		t.Errorf("unmatch value, got:%v, want:%v", got, want)
+	}
+
+	verbose := fmt.Sprintf("%+v", err)
+	data, err3 := errstack.MarshalJSON(err2)
+	if err3 != nil {
+		t.Fatal(err3)
+	}
+	for _, output := range []string{verbose, string(data)} {
+		for _, secret := range []string{"t0k3n", "p4ss", "foo@example.com"} {
+			if strings.Contains(output, secret) {
+				t.Errorf("secret is not redacted, got:%s", output)
+			}
+		}
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	if err := errstack.Redacted(nil); err != nil {
+		t.Errorf("unmatch result, got:%v, want:nil", err)
+	}
+
+	inner := errors.New("my error")
+	err := errstack.Redacted(errstack.WithLV(inner).Secret("password", "p4ss"))
+	if got, want := err.Error(), "my error"; got != want {
+		t.Errorf("unmatch message, got:%s, want:%s", got, want)
+	}
+	if got, want := errors.Is(err, inner), true; got != want {
+		t.Errorf("unmatch Is result, got:%v, want:%v", got, want)
+	}
+	want := []string{"password", errstack.RedactedValue}
+	if got := errstack.LVUnredacted(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+	}
+}
@@ -0,0 +1,251 @@
+package errstack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+)
+
+// RemoteError is an error rebuilt from the encoding of another error,
+// which may have been created in another process.
+//
+// RemoteError can be encoded with encoding/json in the format described
+// in MarshalJSON, and with encoding/gob using the exported fields.
+type RemoteError struct {
+	// Msg is the message of the original error.
+	Msg string
+
+	// Sentinel is the name of the sentinel error registered with
+	// RegisterSentinel if the original error is one of them.
+	Sentinel string
+
+	// Pairs is the labels and values of the original error.
+	Pairs []Field
+
+	// Frames is the stack call frames of the original error.
+	// Their PC and Entry are cleared.
+	Frames []Frame
+
+	// Cause is the error which the original error wrapped with the
+	// Unwrap() error method.
+	Cause *RemoteError
+
+	// Causes is the errors which the original error wrapped with the
+	// Unwrap() []error method.
+	Causes []*RemoteError
+}
+
+var sentinels struct {
+	mu    sync.RWMutex
+	names []string
+	errs  []error
+}
+
+func init() {
+	RegisterSentinel("io.EOF", io.EOF)
+	RegisterSentinel("io.ErrUnexpectedEOF", io.ErrUnexpectedEOF)
+	RegisterSentinel("os.ErrInvalid", os.ErrInvalid)
+	RegisterSentinel("os.ErrPermission", os.ErrPermission)
+	RegisterSentinel("os.ErrExist", os.ErrExist)
+	RegisterSentinel("os.ErrNotExist", os.ErrNotExist)
+	RegisterSentinel("os.ErrClosed", os.ErrClosed)
+	RegisterSentinel("context.Canceled", context.Canceled)
+	RegisterSentinel("context.DeadlineExceeded", context.DeadlineExceeded)
+}
+
+// RegisterSentinel registers a sentinel error with the name.
+//
+// When an error whose chain has err is encoded, the name is recorded,
+// and errors.Is(remoteErr, err) reports true for the RemoteError decoded
+// from the encoding if the same name is registered in the decoding process.
+//
+// Sentinel errors in the io, os and context packages are registered with
+// their qualified names such as "os.ErrNotExist" by default.
+//
+// RegisterSentinel panics if the type of err is not comparable.
+// If the name is already registered, the error for the name is replaced.
+func RegisterSentinel(name string, err error) {
+	if err == nil || !reflect.TypeOf(err).Comparable() {
+		panic("err must be a non-nil comparable error")
+	}
+
+	sentinels.mu.Lock()
+	defer sentinels.mu.Unlock()
+	for i, name2 := range sentinels.names {
+		if name2 == name {
+			sentinels.errs[i] = err
+			return
+		}
+	}
+	sentinels.names = append(sentinels.names, name)
+	sentinels.errs = append(sentinels.errs, err)
+}
+
+func sentinelName(err error) string {
+	sentinels.mu.RLock()
+	defer sentinels.mu.RUnlock()
+	for i, err2 := range sentinels.errs {
+		if err == err2 {
+			return sentinels.names[i]
+		}
+	}
+	return ""
+}
+
+func sentinelError(name string) error {
+	sentinels.mu.RLock()
+	defer sentinels.mu.RUnlock()
+	for i, name2 := range sentinels.names {
+		if name2 == name {
+			return sentinels.errs[i]
+		}
+	}
+	return nil
+}
+
+// Encode converts err to a RemoteError which can be sent to another
+// process with encoding/json or encoding/gob.
+// It returns nil if err is nil.
+//
+// See MarshalJSON for how the errors in err's tree are converted.
+func Encode(err error) *RemoteError {
+	if err == nil {
+		return nil
+	}
+	var enc encoder
+	return enc.encode(err)
+}
+
+// Decode decodes the JSON encoding of an error returned by MarshalJSON.
+// It returns nil without an error for the JSON null.
+func Decode(data []byte) (*RemoteError, error) {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil, nil
+	}
+	var e RemoteError
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+type encoder struct {
+	stacks [][]Frame
+	fields [][]Field
+}
+
+func (enc *encoder) encode(err error) *RemoteError {
+	e := &RemoteError{Msg: err.Error()}
+	for {
+		if e.Sentinel == "" {
+			if e2, ok := err.(*RemoteError); ok {
+				e.Sentinel = e2.Sentinel
+			} else {
+				e.Sentinel = sentinelName(err)
+			}
+		}
+		if e.Pairs == nil {
+			if fields := nodeFields(err); len(fields) > 0 && !containsFields(enc.fields, fields) {
+				enc.fields = append(enc.fields, fields)
+				e.Pairs = fields
+			}
+		}
+		if e2, ok := err.(interface{ Stack() []Frame }); ok && e.Frames == nil {
+			if s := e2.Stack(); len(s) > 0 && !containsFrames(enc.stacks, s) {
+				enc.stacks = append(enc.stacks, s)
+				e.Frames = remoteFrames(s)
+			}
+		}
+
+		switch e2 := err.(type) {
+		case interface{ Unwrap() error }:
+			next := e2.Unwrap()
+			if next == nil {
+				return e
+			}
+			if next.Error() == e.Msg {
+				err = next
+				continue
+			}
+			e.Cause = enc.encode(next)
+		case interface{ Unwrap() []error }:
+			for _, next := range e2.Unwrap() {
+				if next != nil {
+					e.Causes = append(e.Causes, enc.encode(next))
+				}
+			}
+		}
+		return e
+	}
+}
+
+// remoteFrames returns a copy of frames whose PC and Entry are cleared,
+// since they are meaningful only in the process where the frames were
+// obtained.
+func remoteFrames(frames []Frame) []Frame {
+	frames2 := make([]Frame, len(frames))
+	for i, f := range frames {
+		f.PC = 0
+		f.Entry = 0
+		frames2[i] = f
+	}
+	return frames2
+}
+
+func (e *RemoteError) Error() string {
+	return e.Msg
+}
+
+// Unwrap returns the Cause, or an error which joins the Causes.
+func (e *RemoteError) Unwrap() error {
+	if e.Cause != nil {
+		return e.Cause
+	}
+	if len(e.Causes) > 0 {
+		errs := make([]error, len(e.Causes))
+		for i, c := range e.Causes {
+			errs[i] = c
+		}
+		return &joinError{errs: errs}
+	}
+	return nil
+}
+
+// Is reports whether the Sentinel of e is registered with target
+// in this process.
+func (e *RemoteError) Is(target error) bool {
+	if e.Sentinel == "" {
+		return false
+	}
+	return sentinelError(e.Sentinel) == target
+}
+
+// Stack returns the Frames.
+func (e *RemoteError) Stack() []Frame {
+	return e.Frames
+}
+
+// LV returns the Pairs formatted with Field.String.
+// Values which should be redacted are replaced with RedactedValue.
+func (e *RemoteError) LV() []string {
+	return fieldsLV(redactFields(e.Pairs))
+}
+
+// Fields returns the Pairs.
+func (e *RemoteError) Fields() []Field {
+	return e.Pairs
+}
+
+// Messages returns the messages of e and the errors in its chain,
+// following the Cause.
+func (e *RemoteError) Messages() []string {
+	var msgs []string
+	for ; e != nil; e = e.Cause {
+		msgs = append(msgs, e.Msg)
+	}
+	return msgs
+}
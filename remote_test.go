@@ -0,0 +1,133 @@
+package errstack_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+var errTestRemoteSentinel = errors.New("remote sentinel")
+
+func init() {
+	errstack.RegisterSentinel("errstack_test.errTestRemoteSentinel", errTestRemoteSentinel)
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		orig := testRemoteLevel2()
+		data, err := errstack.MarshalJSON(orig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := errstack.Decode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testRemoteError(t, got, orig)
+	})
+	t.Run("gob", func(t *testing.T) {
+		orig := testRemoteLevel2()
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(errstack.Encode(orig)); err != nil {
+			t.Fatal(err)
+		}
+		var got errstack.RemoteError
+		if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		testRemoteError(t, &got, orig)
+		for _, f := range errstack.Stack(&got) {
+			if f.PC != 0 || f.Entry != 0 {
+				t.Errorf("pc must be cleared, got PC:%#x, Entry:%#x", f.PC, f.Entry)
+			}
+		}
+	})
+	t.Run("registeredSentinel", func(t *testing.T) {
+		data, err := errstack.MarshalJSON(errstack.Errorf("outer: %w", errTestRemoteSentinel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := errstack.Decode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := errors.Is(got, errTestRemoteSentinel), true; got != want {
+			t.Errorf("unmatch Is result, got:%v, want:%v", got, want)
+		}
+		if got, want := errors.Is(got, os.ErrNotExist), false; got != want {
+			t.Errorf("unmatch Is result, got:%v, want:%v", got, want)
+		}
+	})
+	t.Run("join", func(t *testing.T) {
+		data, err := errstack.MarshalJSON(errstack.Join(os.ErrExist, testRemoteLevel1()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := errstack.Decode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := errors.Is(got, os.ErrExist), true; got != want {
+			t.Errorf("unmatch Is result, got:%v, want:%v", got, want)
+		}
+		if got, want := len(errstack.Stacks(got)), 2; got != want {
+			t.Errorf("unmatch stack count, got:%d, want:%d", got, want)
+		}
+		if got, want := errstack.LV(got), []string{"reqID", "req1"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+		}
+	})
+	t.Run("null", func(t *testing.T) {
+		got, err := errstack.Decode([]byte("null"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != nil {
+			t.Errorf("unmatch result, got:%v, want:nil", got)
+		}
+	})
+}
+
+func testRemoteError(t *testing.T, got *errstack.RemoteError, orig error) {
+	t.Helper()
+	if got, want := got.Error(), orig.Error(); got != want {
+		t.Errorf("unmatch message, got:%q, want:%q", got, want)
+	}
+	if got, want := got.Messages(), []string{"top: middle: file does not exist", "middle: file does not exist", "file does not exist"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unmatch messages, got:%q, want:%q", got, want)
+	}
+	if got, want := testFramesWithoutPC(errstack.Stack(got)), testFramesWithoutPC(errstack.Stack(orig)); !reflect.DeepEqual(got, want) {
+		t.Errorf("unmatch stack, got:%v, want:%v", got, want)
+	}
+	if got, want := errstack.LV(got), errstack.LV(orig); !reflect.DeepEqual(got, want) {
+		t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+	}
+	if got, want := errors.Is(got, os.ErrNotExist), true; got != want {
+		t.Errorf("unmatch Is result, got:%v, want:%v", got, want)
+	}
+}
+
+// testFramesWithoutPC returns a copy of frames whose PC and Entry are
+// cleared, since those are not encoded in JSON.
+func testFramesWithoutPC(frames []errstack.Frame) []errstack.Frame {
+	frames2 := make([]errstack.Frame, len(frames))
+	for i, f := range frames {
+		f.PC = 0
+		f.Entry = 0
+		frames2[i] = f
+	}
+	return frames2
+}
+
+func testRemoteLevel2() error {
+	return fmt.Errorf("top: %w", testRemoteLevel1())
+}
+func testRemoteLevel1() error {
+	return errstack.WithLV(errstack.Errorf("middle: %w", os.ErrNotExist), "reqID", "req1")
+}
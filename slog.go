@@ -0,0 +1,155 @@
+//go:build go1.21
+// +build go1.21
+
+package errstack
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// LogValue implements slog.LogValuer.
+// It returns the same value as the LogValue function.
+func (e *errorWithStack) LogValue() slog.Value {
+	return LogValue(e)
+}
+
+// LogValue implements slog.LogValuer.
+// It returns the same value as the LogValue function.
+func (e *errorWithLV) LogValue() slog.Value {
+	return LogValue(e)
+}
+
+// LogValue implements slog.LogValuer.
+// It returns the same value as the LogValue function.
+func (e *joinError) LogValue() slog.Value {
+	return LogValue(e)
+}
+
+// LogValue implements slog.LogValuer.
+// It returns the same value as the LogValue function.
+func (e *RemoteError) LogValue() slog.Value {
+	return LogValue(e)
+}
+
+// LogValue implements slog.LogValuer.
+// It returns the same value as the LogValue function.
+func (e *redactedError) LogValue() slog.Value {
+	return LogValue(e)
+}
+
+// LogValue returns a slog group value for err.
+//
+// The group has the error message with the "msg" key, the fields
+// obtained with Fields as attributes of the corresponding kinds, in
+// which values which should be redacted are replaced, and
+// the stack call frames obtained with Stack as a list of strings
+// formatted with Frame.String with the "stack" key.
+// The "stack" attribute is omitted if err has no stack call frames.
+//
+// A field whose label is "msg" or "stack" is added with the label
+// prefixed with "lv.", such as "lv.msg", so that the keys do not collide.
+func LogValue(err error) slog.Value {
+	fields := Fields(err)
+	attrs := make([]slog.Attr, 0, 2+len(fields))
+	attrs = append(attrs, slog.String("msg", err.Error()))
+	n := len(attrs)
+	attrs = appendFieldAttrs(attrs, fields)
+	for i := n; i < len(attrs); i++ {
+		if attrs[i].Key == "msg" || attrs[i].Key == "stack" {
+			attrs[i].Key = "lv." + attrs[i].Key
+		}
+	}
+	if s := Stack(err); s != nil {
+		frames := make([]string, len(s))
+		for i := range s {
+			frames[i] = s[i].String()
+		}
+		attrs = append(attrs, slog.Any("stack", frames))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func appendFieldAttrs(attrs []slog.Attr, fields []Field) []slog.Attr {
+	for _, f := range fields {
+		var v slog.Value
+		switch fv := f.Value().(type) {
+		case int64:
+			v = slog.Int64Value(fv)
+		case uint64:
+			v = slog.Uint64Value(fv)
+		case float64:
+			v = slog.Float64Value(fv)
+		case bool:
+			v = slog.BoolValue(fv)
+		case time.Time:
+			v = slog.TimeValue(fv)
+		case time.Duration:
+			v = slog.DurationValue(fv)
+		case []Field:
+			v = slog.GroupValue(appendFieldAttrs(nil, fv)...)
+		default:
+			v = slog.StringValue(f.String())
+		}
+		attrs = append(attrs, slog.Attr{Key: f.Label, Value: v})
+	}
+	return attrs
+}
+
+type slogHandler struct {
+	h slog.Handler
+}
+
+// NewSlogHandler returns a slog.Handler which replaces the value of
+// every attribute holding an error with the value returned by LogValue,
+// and passes the record to h.
+//
+// Errors wrapped by other libraries, for example with fmt.Errorf,
+// are expanded as well as errors created with this package.
+func NewSlogHandler(h slog.Handler) slog.Handler {
+	return &slogHandler{h: h}
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	r2 := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		r2.AddAttrs(expandErrorAttr(a))
+		return true
+	})
+	return h.h.Handle(ctx, r2)
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	attrs2 := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		attrs2[i] = expandErrorAttr(a)
+	}
+	return &slogHandler{h: h.h.WithAttrs(attrs2)}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	return &slogHandler{h: h.h.WithGroup(name)}
+}
+
+func expandErrorAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.Attr{Key: a.Key, Value: LogValue(err)}
+		}
+	case slog.KindGroup:
+		group := v.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, a2 := range group {
+			attrs[i] = expandErrorAttr(a2)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
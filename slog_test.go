@@ -0,0 +1,140 @@
+//go:build go1.21
+// +build go1.21
+
+package errstack_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestLogValue(t *testing.T) {
+	t.Run("logValuer", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, nil))
+		logger.Error("failed", slog.Any("err", testSlogLevel1()))
+		testSlogErrorGroup(t, buf.Bytes())
+	})
+	t.Run("handler", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(errstack.NewSlogHandler(slog.NewJSONHandler(&buf, nil)))
+		logger.Error("failed", slog.Any("err", fmt.Errorf("outer: %w", testSlogLevel1())))
+		got := testSlogErrorGroup(t, buf.Bytes())
+		if got, want := got["msg"], "outer: my error"; got != want {
+			t.Errorf("unmatch msg, got:%v, want:%v", got, want)
+		}
+	})
+	t.Run("handlerWithAttrsAndGroup", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(errstack.NewSlogHandler(slog.NewJSONHandler(&buf, nil)))
+		logger = logger.With(slog.Any("err", errors.New("plain"))).WithGroup("g")
+		logger.Error("failed", slog.Group("sub", slog.Any("err", testSlogLevel1())))
+		var m map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := m["err"], map[string]interface{}{"msg": "plain"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("unmatch err, got:%v, want:%v", got, want)
+		}
+		g := m["g"].(map[string]interface{})
+		sub := g["sub"].(map[string]interface{})
+		if got, want := sub["err"].(map[string]interface{})["reqID"], "req1"; got != want {
+			t.Errorf("unmatch reqID, got:%v, want:%v", got, want)
+		}
+	})
+}
+
+func TestLogValueRedacted(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Error("failed", slog.Any("err", errstack.WithLV(errstack.New("my error")).Secret("password", "p4ss")))
+	if got := buf.String(); strings.Contains(got, "p4ss") || !strings.Contains(got, errstack.RedactedValue) {
+		t.Errorf("secret is not redacted, got:%s", got)
+	}
+}
+
+func testSlogLevel1() error {
+	return errstack.WithLV(errstack.New("my error"), "reqID", "req1")
+}
+
+func testSlogErrorGroup(t *testing.T, data []byte) map[string]interface{} {
+	t.Helper()
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	e, ok := m["err"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("err is not a group, got:%s", data)
+	}
+	if got, want := e["reqID"], "req1"; got != want {
+		t.Errorf("unmatch reqID, got:%v, want:%v", got, want)
+	}
+	stack, ok := e["stack"].([]interface{})
+	if !ok || len(stack) == 0 {
+		t.Fatalf("stack is not a list, got:%s", data)
+	}
+	if got, want := stack[0].(string), "github.com/hnakamur/errstack_test.testSlogLevel1@"; !strings.HasPrefix(got, want) {
+		t.Errorf("unmatch stack[0], got:%s, want prefix:%s", got, want)
+	}
+	return e
+}
+
+func TestLogValueFieldLiteral(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	err := errstack.WithLV(errors.New("my error")).Group("g", errstack.Field{Label: "t", Kind: errstack.KindTime})
+	logger.Error("failed", slog.Any("err", err))
+	if got := buf.String(); !strings.Contains(got, `"g":{"t":""}`) {
+		t.Errorf("unmatch result, got:%s", got)
+	}
+}
+
+func TestLogValueReservedLabels(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Error("failed", slog.Any("err", errstack.WithLV(errstack.New("my error"), "msg", "m1", "stack", "s1")))
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	e := m["err"].(map[string]interface{})
+	if got, want := e["msg"], "my error"; got != want {
+		t.Errorf("unmatch msg, got:%v, want:%v", got, want)
+	}
+	if got, want := e["lv.msg"], "m1"; got != want {
+		t.Errorf("unmatch lv.msg, got:%v, want:%v", got, want)
+	}
+	if got, want := e["lv.stack"], "s1"; got != want {
+		t.Errorf("unmatch lv.stack, got:%v, want:%v", got, want)
+	}
+	if _, ok := e["stack"].([]interface{}); !ok {
+		t.Errorf("stack is not a list, got:%s", buf.Bytes())
+	}
+	if got, want := strings.Count(buf.String(), `"msg":`), 2; got != want {
+		t.Errorf("unmatch msg key count, got:%d, want:%d, log:%s", got, want, buf.Bytes())
+	}
+}
+
+func TestLogValueRemote(t *testing.T) {
+	data, err := errstack.MarshalJSON(testSlogLevel1())
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, err := errstack.Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Error("failed", slog.Any("err", remote))
+	testSlogErrorGroup(t, buf.Bytes())
+}
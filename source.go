@@ -0,0 +1,144 @@
+package errstack
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SourceLine is a line of source code.
+type SourceLine struct {
+	// Line is the line number starting at 1.
+	Line int
+
+	// Text is the content of the line without the newline.
+	Text string
+
+	// Current is true for the line of the frame.
+	Current bool
+}
+
+type sourceFile struct {
+	lines []string
+	err   error
+}
+
+// maxSourceFiles is the maximum count of files in the source cache.
+const maxSourceFiles = 64
+
+var sourceCache struct {
+	mu    sync.Mutex
+	files map[string]*sourceFile
+	order []string
+}
+
+// Source returns the lines of the source file of f within context lines
+// around the line of f. The result of reading a file is cached, including
+// a failure.
+//
+// The file is located with the program counter of f if it is available
+// and it points to the function of f in this binary, so that the path
+// rewritten with SetPathOptions does not matter.
+// Otherwise a path starting with "$GOROOT" is read from GOROOT of the
+// build machine, and other paths are read as is.
+func (f *Frame) Source(context int) ([]SourceLine, error) {
+	lines, err := readSourceLines(f.sourceFilename())
+	if err != nil {
+		return nil, err
+	}
+	if f.Line < 1 || f.Line > len(lines) {
+		return nil, fmt.Errorf("errstack: line %d is out of range of %s", f.Line, f.Path)
+	}
+	if context < 0 {
+		context = 0
+	}
+	start := f.Line - context
+	if start < 1 {
+		start = 1
+	}
+	end := f.Line + context
+	if end > len(lines) {
+		end = len(lines)
+	}
+	src := make([]SourceLine, 0, end-start+1)
+	for i := start; i <= end; i++ {
+		src = append(src, SourceLine{Line: i, Text: lines[i-1], Current: i == f.Line})
+	}
+	return src, nil
+}
+
+// sourceFilename returns the name of the source file of f before it was
+// rewritten with SetPathOptions.
+func (f *Frame) sourceFilename() string {
+	if f.PC != 0 {
+		if fn := runtime.FuncForPC(f.PC); fn != nil && fn.Name() == f.Name {
+			if file, _ := fn.FileLine(f.PC); file != "" {
+				return file
+			}
+		}
+	}
+	if strings.HasPrefix(f.Path, "$GOROOT/") {
+		return gorootDir() + f.Path[len("$GOROOT"):]
+	}
+	return f.Path
+}
+
+func readSourceLines(filename string) ([]string, error) {
+	sourceCache.mu.Lock()
+	sf, ok := sourceCache.files[filename]
+	sourceCache.mu.Unlock()
+	if ok {
+		return sf.lines, sf.err
+	}
+
+	sf = &sourceFile{}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		sf.err = err
+	} else {
+		sf.lines = strings.Split(strings.Replace(string(data), "\r\n", "\n", -1), "\n")
+	}
+
+	sourceCache.mu.Lock()
+	defer sourceCache.mu.Unlock()
+	if _, ok := sourceCache.files[filename]; !ok {
+		if sourceCache.files == nil {
+			sourceCache.files = make(map[string]*sourceFile)
+		}
+		if len(sourceCache.order) >= maxSourceFiles {
+			delete(sourceCache.files, sourceCache.order[0])
+			sourceCache.order = sourceCache.order[1:]
+		}
+		sourceCache.files[filename] = sf
+		sourceCache.order = append(sourceCache.order, filename)
+	}
+	return sf.lines, sf.err
+}
+
+// writeSource writes the source lines within context lines around the
+// line of f, one line per line in the "\t> 12 | text" layout. Nothing is
+// written if the source is not available.
+func writeSource(b *bytes.Buffer, f *Frame, context int) {
+	src, err := f.Source(context)
+	if err != nil {
+		return
+	}
+	width := len(strconv.Itoa(src[len(src)-1].Line))
+	for _, l := range src {
+		b.WriteString("\n\t")
+		if l.Current {
+			b.WriteString("> ")
+		} else {
+			b.WriteString("  ")
+		}
+		num := strconv.Itoa(l.Line)
+		b.WriteString(strings.Repeat(" ", width-len(num)))
+		b.WriteString(num)
+		b.WriteString(" | ")
+		b.WriteString(l.Text)
+	}
+}
@@ -0,0 +1,115 @@
+package errstack_test
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestFrameSource(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		f := errstack.Stack(testSourceLevel1())[0]
+		src, err := f.Source(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(src), 3; got != want {
+			t.Fatalf("unmatch line count, got:%d, want:%d", got, want)
+		}
+		for i, l := range src {
+			if got, want := l.Line, f.Line-1+i; got != want {
+				t.Errorf("unmatch src[%d].Line, got:%d, want:%d", i, got, want)
+			}
+			if got, want := l.Current, i == 1; got != want {
+				t.Errorf("unmatch src[%d].Current, got:%v, want:%v", i, got, want)
+			}
+		}
+		if got, want := src[1].Text, "\treturn errstack.New(\"my error\")"; got != want {
+			t.Errorf("unmatch current line, got:%q, want:%q", got, want)
+		}
+		if got, want := src[2].Text, "}"; got != want {
+			t.Errorf("unmatch next line, got:%q, want:%q", got, want)
+		}
+	})
+	t.Run("trimmedPath", func(t *testing.T) {
+		dir, err := os.Getwd()
+		if err != nil {
+			t.Fatal(err)
+		}
+		errstack.SetPathOptions(errstack.PathOptions{TrimPrefix: dir})
+		defer errstack.SetPathOptions(errstack.PathOptions{})
+
+		f := errstack.Stack(testSourceLevel1())[0]
+		if got, want := f.Path, "source_test.go"; got != want {
+			t.Fatalf("unmatch path, got:%s, want:%s", got, want)
+		}
+		if err := os.Chdir(os.TempDir()); err != nil {
+			t.Fatal(err)
+		}
+		defer os.Chdir(dir)
+		src, err := f.Source(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := src[0].Text, "\treturn errstack.New(\"my error\")"; got != want {
+			t.Errorf("unmatch current line, got:%q, want:%q", got, want)
+		}
+	})
+	t.Run("pcOfOtherFunction", func(t *testing.T) {
+		local := errstack.Stack(testSourceLevel1())[0]
+		f := errstack.Frame{Name: "example.com/app.F", Path: "/nonexistent/app.go", Line: 1, PC: local.PC, Entry: local.Entry}
+		if src, err := f.Source(0); err == nil {
+			t.Errorf("source of the local function must not be used, got:%v", src)
+		}
+	})
+	t.Run("missing", func(t *testing.T) {
+		f := errstack.Frame{Name: "example.com/app.F", Path: "/nonexistent/app.go", Line: 1}
+		if _, err := f.Source(1); err == nil {
+			t.Errorf("error must be returned for a missing file")
+		}
+	})
+	t.Run("outOfRange", func(t *testing.T) {
+		f := errstack.Stack(testSourceLevel1())[0]
+		f.Line = 1 << 20
+		if _, err := f.Source(1); err == nil {
+			t.Errorf("error must be returned for a line out of range")
+		}
+	})
+}
+
+func TestFormatSource(t *testing.T) {
+	err := testSourceLevel1()
+	f := errstack.Stack(err)[0]
+	got := fmt.Sprintf("%+.1v", err)
+	want := "my error\n" +
+		"github.com/hnakamur/errstack_test.testSourceLevel1\n" +
+		"\t" + f.Path + ":" + strconv.Itoa(f.Line) + "\n" +
+		"\t  " + strconv.Itoa(f.Line-1) + " | func testSourceLevel1() error {\n" +
+		"\t> " + strconv.Itoa(f.Line) + " | \treturn errstack.New(\"my error\")\n" +
+		"\t  " + strconv.Itoa(f.Line+1) + " | }\n"
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("unmatch result,\n got:%s,\nwant prefix:%s", got, want)
+	}
+
+	got = fmt.Sprintf("%+.2v", errstack.Wrap(testSourceMissingError{}))
+	want = "my error\nexample.com/app.F\n\t/nonexistent/app.go:12"
+	if got != want {
+		t.Errorf("unmatch result for missing file,\n got:%s,\nwant:%s", got, want)
+	}
+}
+
+type testSourceMissingError struct{}
+
+func (testSourceMissingError) Error() string { return "my error" }
+
+func (testSourceMissingError) Stack() []errstack.Frame {
+	return []errstack.Frame{{Name: "example.com/app.F", Path: "/nonexistent/app.go", Line: 12}}
+}
+
+func testSourceLevel1() error {
+	return errstack.New("my error")
+}
@@ -0,0 +1,123 @@
+package errstack
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+// WrapPoint is a place where an error was wrapped.
+type WrapPoint struct {
+	// Frame is the call stack frame of the caller of Errorf, Wrap
+	// or Wrapf.
+	Frame Frame
+
+	// Message is the message of the error at this point.
+	Message string
+}
+
+var wrapPointRecording uint32
+
+// SetWrapPointRecording enables or disables recording of wrap points.
+//
+// If it is enabled, Errorf, ErrorfCtx, ErrorfSkip, Wrap, Wrapf and the
+// corresponding methods of Creator record the call stack frame of
+// their caller, which is cheaper than recording the whole stack.
+// The recorded points can be obtained with Trace.
+//
+// Recording is disabled by default.
+func SetWrapPointRecording(enabled bool) {
+	var v uint32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreUint32(&wrapPointRecording, v)
+}
+
+// wrapPointStack returns the call stack frame of the caller of the
+// function calling wrapPointStack, or nil if recording wrap points is
+// disabled. The skip argument is the same as that of callers.
+func wrapPointStack(skip int) *stack {
+	if atomic.LoadUint32(&wrapPointRecording) == 0 {
+		return nil
+	}
+	pcs := make([]uintptr, 1)
+	n := runtime.Callers(skip, pcs)
+	return &stack{pcs: pcs[:n]}
+}
+
+// wrapPoint returns the wrap point of e, or false if it is not recorded.
+func (e *errorWithStack) wrapPoint() (WrapPoint, bool) {
+	if e.wrap == nil {
+		return WrapPoint{}, false
+	}
+	frames := e.wrap.Frames()
+	if len(frames) == 0 {
+		return WrapPoint{}, false
+	}
+	return WrapPoint{Frame: frames[0], Message: e.Error()}, true
+}
+
+// Trace returns the wrap points recorded in err's tree, in the order
+// from the innermost error to the outermost one, which is the route the
+// error took up through the layers.
+//
+// Wrap points are recorded only while SetWrapPointRecording is enabled.
+func Trace(err error) []WrapPoint {
+	var points []WrapPoint
+	walk(err, func(err error) bool {
+		if e2, ok := err.(interface{ wrapPoint() (WrapPoint, bool) }); ok {
+			if p, ok := e2.wrapPoint(); ok {
+				points = append(points, p)
+			}
+		}
+		return true
+	})
+	return reverseWrapPoints(points)
+}
+
+// chainTrace is the same as Trace except that it does not descend into
+// the errors joined with Join or any other multi-errors.
+func chainTrace(err error) []WrapPoint {
+	var points []WrapPoint
+	for err != nil {
+		if e2, ok := err.(interface{ wrapPoint() (WrapPoint, bool) }); ok {
+			if p, ok := e2.wrapPoint(); ok {
+				points = append(points, p)
+			}
+		}
+		e2, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = e2.Unwrap()
+	}
+	return reverseWrapPoints(points)
+}
+
+func reverseWrapPoints(points []WrapPoint) []WrapPoint {
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+	return points
+}
+
+// writeTrace writes the wrap points, one point per line in the
+// "function: message\n\tfile:line" layout.
+func writeTrace(b *bytes.Buffer, points []WrapPoint) {
+	if len(points) == 0 {
+		return
+	}
+	b.WriteString("\nerror return trace:")
+	for _, p := range points {
+		b.WriteByte('\n')
+		b.WriteString(p.Frame.Name)
+		b.WriteString(": ")
+		b.WriteString(p.Message)
+		b.WriteString("\n\t")
+		b.WriteString(p.Frame.Path)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(p.Frame.Line))
+	}
+}
@@ -0,0 +1,70 @@
+package errstack_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestTrace(t *testing.T) {
+	t.Run("enabled", func(t *testing.T) {
+		errstack.SetWrapPointRecording(true)
+		defer errstack.SetWrapPointRecording(false)
+
+		err := testTraceLevel3()
+		want := []struct {
+			name string
+			msg  string
+		}{
+			{name: "github.com/hnakamur/errstack_test.testTraceLevel1", msg: "level1: file does not exist"},
+			{name: "github.com/hnakamur/errstack_test.testTraceLevel2", msg: "level1: file does not exist"},
+			{name: "github.com/hnakamur/errstack_test.testTraceLevel3", msg: "level3: level1: file does not exist"},
+		}
+		points := errstack.Trace(err)
+		if got, want := len(points), len(want); got != want {
+			t.Fatalf("unmatch point count, got:%d, want:%d, points:%v", got, want, points)
+		}
+		for i, w := range want {
+			if got, want := points[i].Frame.Name, w.name; got != want {
+				t.Errorf("unmatch points[%d].Frame.Name, got:%s, want:%s", i, got, want)
+			}
+			if got, want := points[i].Message, w.msg; got != want {
+				t.Errorf("unmatch points[%d].Message, got:%s, want:%s", i, got, want)
+			}
+		}
+
+		got := fmt.Sprintf("%+v", err)
+		wantTrace := "\nerror return trace:\n" +
+			"github.com/hnakamur/errstack_test.testTraceLevel1: level1: file does not exist\n\t"
+		if !strings.Contains(got, wantTrace) {
+			t.Errorf("error return trace not found, got:%s", got)
+		}
+		if i, j := strings.Index(got, "\ngithub.com/hnakamur/errstack_test.testTraceLevel1\n"), strings.Index(got, wantTrace); i < 0 || j < i {
+			t.Errorf("error return trace must follow the stack, got:%s", got)
+		}
+	})
+	t.Run("disabled", func(t *testing.T) {
+		err := testTraceLevel3()
+		if got := errstack.Trace(err); got != nil {
+			t.Errorf("unmatch trace, got:%v, want:nil", got)
+		}
+		if got := fmt.Sprintf("%+v", err); strings.Contains(got, "error return trace:") {
+			t.Errorf("error return trace must not be printed, got:%s", got)
+		}
+	})
+}
+
+func testTraceLevel3() error {
+	return errstack.Wrapf(testTraceLevel2(), "level3")
+}
+
+func testTraceLevel2() error {
+	return errstack.Wrap(testTraceLevel1())
+}
+
+func testTraceLevel1() error {
+	return errstack.Errorf("level1: %w", os.ErrNotExist)
+}
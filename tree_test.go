@@ -0,0 +1,83 @@
+//go:build go1.20
+// +build go1.20
+
+package errstack_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestStackTree(t *testing.T) {
+	t.Run("join", func(t *testing.T) {
+		err := errors.Join(errors.New("plain"), testTreeLevel1())
+		testStackFrameNames(t, errstack.Stack(err), []string{
+			"github.com/hnakamur/errstack_test.testTreeLevel1",
+			"github.com/hnakamur/errstack_test.TestStackTree.func1",
+		})
+	})
+	t.Run("multipleW", func(t *testing.T) {
+		err := fmt.Errorf("a: %w, b: %w", errors.New("plain"), testTreeLevel1())
+		testStackFrameNames(t, errstack.Stack(err), []string{
+			"github.com/hnakamur/errstack_test.testTreeLevel1",
+			"github.com/hnakamur/errstack_test.TestStackTree.func2",
+		})
+	})
+	t.Run("errorfInheritsFromJoin", func(t *testing.T) {
+		err := errstack.Errorf("outer: %w", errors.Join(errors.New("plain"), testTreeLevel1()))
+		testStackFrameNames(t, errstack.Stack(err), []string{
+			"github.com/hnakamur/errstack_test.testTreeLevel1",
+			"github.com/hnakamur/errstack_test.TestStackTree.func3",
+		})
+	})
+}
+
+func TestStacks(t *testing.T) {
+	t.Run("join", func(t *testing.T) {
+		err := errors.Join(testTreeLevel1(), nil, testTreeLevel1b())
+		ss := errstack.Stacks(err)
+		if got, want := len(ss), 2; got != want {
+			t.Fatalf("unmatch stack count, got:%d, want:%d", got, want)
+		}
+		testStackFrameNames(t, ss[0], []string{"github.com/hnakamur/errstack_test.testTreeLevel1"})
+		testStackFrameNames(t, ss[1], []string{"github.com/hnakamur/errstack_test.testTreeLevel1b"})
+	})
+	t.Run("inheritedOnlyOnce", func(t *testing.T) {
+		err := errstack.Errorf("outer: %w", testTreeLevel1())
+		if got, want := len(errstack.Stacks(err)), 1; got != want {
+			t.Errorf("unmatch stack count, got:%d, want:%d", got, want)
+		}
+	})
+	t.Run("nil", func(t *testing.T) {
+		if got := errstack.Stacks(nil); got != nil {
+			t.Errorf("unmatch stacks, got:%v, want:nil", got)
+		}
+	})
+}
+
+func TestLVTree(t *testing.T) {
+	err := fmt.Errorf("a: %w, b: %w",
+		errors.New("plain"),
+		errstack.WithLV(errors.New("my error"), "reqID", "req1"))
+	if got, want := errstack.LV(err), []string{"reqID", "req1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+	}
+}
+
+func TestAllLV(t *testing.T) {
+	err := errors.Join(
+		errstack.WithLV(errors.New("error1"), "item", "1"),
+		errstack.Errorf("error2: %w", errstack.WithLV(errors.New("my error"), "item", "2")),
+	)
+	want := [][]string{{"item", "1"}, {"item", "2"}}
+	if got := errstack.AllLV(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("lv unmatch, got:%v, want:%v", got, want)
+	}
+}
+
+func testTreeLevel1() error  { return errstack.New("my error") }
+func testTreeLevel1b() error { return errstack.New("my error b") }
@@ -0,0 +1,120 @@
+package errstack
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// PathOptions is the configuration of rewriting the paths of stack call
+// frames.
+type PathOptions struct {
+	// TrimGOROOT replaces GOROOT at the start of paths with "$GOROOT".
+	TrimGOROOT bool
+
+	// TrimModCache rewrites paths in the module cache to the form
+	// "module@version/file.go".
+	TrimModCache bool
+
+	// TrimPrefix is removed from paths which are in the directory, for
+	// example the workspace directory of the build machine.
+	TrimPrefix string
+}
+
+var pathOptions atomic.Value
+
+var goroot struct {
+	once sync.Once
+	dir  string
+}
+
+// SetPathOptions sets the configuration of rewriting the paths of stack
+// call frames. Paths are rewritten with TrimPath when the frames are
+// resolved, so the configuration also applies to errors created before
+// SetPathOptions is called whose frames have not been obtained yet.
+//
+// By default, paths are not rewritten.
+func SetPathOptions(opts PathOptions) {
+	pathOptions.Store(opts)
+}
+
+func getPathOptions() PathOptions {
+	opts, _ := pathOptions.Load().(PathOptions)
+	return opts
+}
+
+// TrimPath rewrites path with the configuration set with SetPathOptions.
+func TrimPath(path string) string {
+	p, _ := getPathOptions().trim(path)
+	return p
+}
+
+// ShortPath returns the path of the file relative to its module or
+// GOROOT, regardless of the configuration set with SetPathOptions.
+// The TrimPrefix of the configuration is removed if it is set.
+//
+// Paths which are not in GOROOT, the module cache or TrimPrefix are
+// shortened to the last directory and the file name.
+func (f *Frame) ShortPath() string {
+	opts := PathOptions{
+		TrimGOROOT:   true,
+		TrimModCache: true,
+		TrimPrefix:   getPathOptions().TrimPrefix,
+	}
+	p, ok := opts.trim(f.Path)
+	if ok || !isAbsPath(p) {
+		return p
+	}
+	i := strings.LastIndexByte(p, '/')
+	if i <= 0 {
+		return p
+	}
+	if j := strings.LastIndexByte(p[:i], '/'); j >= 0 {
+		return p[j+1:]
+	}
+	return p
+}
+
+// trim rewrites path and reports whether path was rewritten.
+func (o PathOptions) trim(path string) (string, bool) {
+	if prefix := strings.TrimSuffix(o.TrimPrefix, "/"); prefix != "" && strings.HasPrefix(path, prefix) {
+		if rest := path[len(prefix):]; rest == "" || rest[0] == '/' {
+			return strings.TrimPrefix(rest, "/"), true
+		}
+	}
+	if o.TrimGOROOT {
+		if dir := gorootDir(); dir != "" && strings.HasPrefix(path, dir+"/") {
+			return "$GOROOT" + path[len(dir):], true
+		}
+	}
+	if o.TrimModCache {
+		if i := strings.Index(path, "/pkg/mod/"); i >= 0 {
+			return path[i+len("/pkg/mod/"):], true
+		}
+	}
+	return path, false
+}
+
+// gorootDir returns GOROOT of the build machine which is detected from
+// the file of a function in the standard library. It returns an empty
+// string if the binary was built with -trimpath.
+func gorootDir() string {
+	goroot.once.Do(func() {
+		fn := runtime.FuncForPC(reflect.ValueOf(runtime.Callers).Pointer())
+		if fn == nil {
+			return
+		}
+		file, _ := fn.FileLine(fn.Entry())
+		if i := strings.LastIndex(file, "/src/runtime/"); i > 0 {
+			goroot.dir = file[:i]
+		}
+	})
+	return goroot.dir
+}
+
+func isAbsPath(path string) bool {
+	return strings.HasPrefix(path, "/") ||
+		(len(path) >= 3 && path[1] == ':' && (path[2] == '/' || path[2] == '\\'))
+}
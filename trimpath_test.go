@@ -0,0 +1,101 @@
+package errstack_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestTrimPath(t *testing.T) {
+	defer errstack.SetPathOptions(errstack.PathOptions{})
+
+	testCases := []struct {
+		opts errstack.PathOptions
+		path string
+		want string
+	}{
+		{
+			opts: errstack.PathOptions{},
+			path: "/home/ci/go/pkg/mod/github.com/foo/bar@v1.2.3/baz/baz.go",
+			want: "/home/ci/go/pkg/mod/github.com/foo/bar@v1.2.3/baz/baz.go",
+		},
+		{
+			opts: errstack.PathOptions{TrimModCache: true},
+			path: "/home/ci/go/pkg/mod/github.com/foo/bar@v1.2.3/baz/baz.go",
+			want: "github.com/foo/bar@v1.2.3/baz/baz.go",
+		},
+		{
+			opts: errstack.PathOptions{TrimModCache: true},
+			path: "/home/ci/work/app/main.go",
+			want: "/home/ci/work/app/main.go",
+		},
+		{
+			opts: errstack.PathOptions{TrimPrefix: "/home/ci/work/"},
+			path: "/home/ci/work/app/main.go",
+			want: "app/main.go",
+		},
+		{
+			opts: errstack.PathOptions{TrimPrefix: "/home/ci/work"},
+			path: "/home/ci/work/app/main.go",
+			want: "app/main.go",
+		},
+		{
+			opts: errstack.PathOptions{TrimPrefix: "/home/ci/work"},
+			path: "/home/ci/workspace2/x.go",
+			want: "/home/ci/workspace2/x.go",
+		},
+		{
+			opts: errstack.PathOptions{TrimPrefix: "/home/ci/work/"},
+			path: "/home/ci/workspace2/x.go",
+			want: "/home/ci/workspace2/x.go",
+		},
+	}
+	for _, tc := range testCases {
+		errstack.SetPathOptions(tc.opts)
+		if got := errstack.TrimPath(tc.path); got != tc.want {
+			t.Errorf("unmatch path for %+v, got:%s, want:%s", tc.opts, got, tc.want)
+		}
+	}
+}
+
+func TestTrimPathGOROOT(t *testing.T) {
+	defer errstack.SetPathOptions(errstack.PathOptions{})
+
+	errstack.SetPathOptions(errstack.PathOptions{TrimGOROOT: true})
+	err := errstack.New("my error")
+	var found bool
+	for _, f := range errstack.Stack(err) {
+		if f.Name != "testing.tRunner" {
+			continue
+		}
+		found = true
+		if got := f.Path; !strings.HasPrefix(got, "$GOROOT/src/testing/") && got != "testing/testing.go" {
+			t.Errorf("unmatch path of testing.tRunner, got:%s", got)
+		}
+		if got, want := f.ShortPath(), f.Path; got != want {
+			t.Errorf("unmatch short path, got:%s, want:%s", got, want)
+		}
+	}
+	if !found {
+		t.Errorf("testing.tRunner not found in stack")
+	}
+}
+
+func TestFrameShortPath(t *testing.T) {
+	testCases := []struct {
+		path string
+		want string
+	}{
+		{path: "/home/ci/go/pkg/mod/github.com/foo/bar@v1.2.3/baz/baz.go", want: "github.com/foo/bar@v1.2.3/baz/baz.go"},
+		{path: "/home/ci/work/app/main.go", want: "app/main.go"},
+		{path: "github.com/foo/bar@v1.2.3/baz.go", want: "github.com/foo/bar@v1.2.3/baz.go"},
+		{path: "/main.go", want: "/main.go"},
+	}
+	for _, tc := range testCases {
+		f := errstack.Frame{Path: tc.path}
+		if got := f.ShortPath(); got != tc.want {
+			t.Errorf("unmatch short path for %s, got:%s, want:%s", tc.path, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package errstack_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/hnakamur/errstack"
+)
+
+func TestWrap(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		if err := errstack.Wrap(nil); err != nil {
+			t.Errorf("unmatch result, got:%v, want:nil", err)
+		}
+		if err := errstack.Wrapf(nil, "open %s", "foo"); err != nil {
+			t.Errorf("unmatch result, got:%v, want:nil", err)
+		}
+	})
+	t.Run("wrap", func(t *testing.T) {
+		err := testWrapLevel2()
+		if got, want := err.Error(), os.ErrNotExist.Error(); got != want {
+			t.Errorf("unmatch message, got:%q, want:%q", got, want)
+		}
+		if got, want := errors.Is(err, os.ErrNotExist), true; got != want {
+			t.Errorf("unmatch Is result, got:%v, want:%v", got, want)
+		}
+		testStackFrameNames(t, errstack.Stack(err), []string{
+			"github.com/hnakamur/errstack_test.testWrapLevel2",
+			"github.com/hnakamur/errstack_test.TestWrap.func2",
+		})
+	})
+	t.Run("wrapf", func(t *testing.T) {
+		err := testWrapfLevel2()
+		if got, want := err.Error(), "open foo: "+os.ErrNotExist.Error(); got != want {
+			t.Errorf("unmatch message, got:%q, want:%q", got, want)
+		}
+		if got, want := errors.Is(err, os.ErrNotExist), true; got != want {
+			t.Errorf("unmatch Is result, got:%v, want:%v", got, want)
+		}
+		testStackFrameNames(t, errstack.Stack(err), []string{
+			"github.com/hnakamur/errstack_test.testWrapfLevel2",
+			"github.com/hnakamur/errstack_test.TestWrap.func3",
+		})
+	})
+	t.Run("inherit", func(t *testing.T) {
+		err := errstack.Wrapf(errstack.Wrap(testWrapLevel1()), "outer")
+		testStackFrameNames(t, errstack.Stack(err), []string{
+			"github.com/hnakamur/errstack_test.testWrapLevel1",
+			"github.com/hnakamur/errstack_test.TestWrap.func4",
+		})
+	})
+}
+
+func testWrapLevel2() error { return errstack.Wrap(os.ErrNotExist) }
+func testWrapLevel1() error { return errstack.New("my error") }
+
+func testWrapfLevel2() error { return errstack.Wrapf(os.ErrNotExist, "open %s", "foo") }